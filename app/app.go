@@ -1,11 +1,12 @@
 package app
 
 import (
-	"github.com/go-playground/validator"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/handler"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/mailer"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
 )
@@ -22,7 +23,12 @@ func New(router *echo.Echo, db *db.Database) *App {
 	}
 }
 
-func (a *App) Run(port string) error {
+// Init connects to the database and sets up the models and mailer the routes depend on
+func (a *App) Init() error {
+	if err := utils.InitJWT(config.Config.JWT); err != nil {
+		return err
+	}
+
 	err := a.DB.Connect()
 	a.DB.WaitForConnection()
 	models.InitModels(a.DB)
@@ -31,8 +37,21 @@ func (a *App) Run(port string) error {
 		return err
 	}
 
+	utils.InitCardSync()
+
+	if sets := config.Config.Cards.StandardSets; len(sets) > 0 {
+		standard := models.FormatStandard
+		standard.Sets = sets
+		models.RegisterDeckFormat(standard)
+	}
+
+	return mailer.Init(config.Config.Mail)
+}
+
+func (a *App) Run(port string) error {
 	// Middleware
-	a.Router.Validator = &handler.Validator{Validator: validator.New()}
+	a.Router.Validator = handler.NewValidator()
+	a.Router.HTTPErrorHandler = handler.HTTPErrorHandler
 	a.Router.Use(middleware.Logger())
 	a.Router.Use(middleware.Recover())
 	a.Router.Pre(middleware.RemoveTrailingSlash())
@@ -42,22 +61,67 @@ func (a *App) Run(port string) error {
 	a.Router.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowCredentials: true,
+		ExposeHeaders:    []string{"X-Total-Count"},
 	}))
 
 	//Routes
 	cardRoutes := a.Router.Group("/cards")
 	cardRoutes.GET("", handler.GetCards)
 	cardRoutes.GET("/:id", handler.GetCard)
+	cardRoutes.GET("/:id/archetypes", handler.GetCardArchetypes)
+	cardRoutes.GET("/:id/history", handler.GetCardHistory)
+
+	patchRoutes := a.Router.Group("/patches")
+	patchRoutes.GET("/:version/changes", handler.GetPatchChanges)
+	patchRoutes.GET("/:version/decks", handler.GetPatchAffectedDecks)
 
 	userRoutes := a.Router.Group("/users")
 	userAuthRoutes := a.Router.Group("/users", utils.JWTMiddleware())
 	userRoutes.POST("/login", handler.Login)
 	userRoutes.POST("/logout", handler.Logout)
+	userRoutes.POST("/refresh", handler.Refresh)
+	userRoutes.POST("/password/forgot", handler.ForgotPassword)
+	userRoutes.POST("/password/reset", handler.ResetPassword)
+	userRoutes.POST("/verify", handler.VerifyEmail)
+	userAuthRoutes.POST("/verify/request", handler.RequestEmailVerification)
 	userRoutes.POST("", handler.Register)
 	userAuthRoutes.GET("/auth", handler.Auth)
+	userAuthRoutes.GET("/sessions", handler.GetSessions)
+	userAuthRoutes.DELETE("/sessions", handler.RevokeAllSessions)
+	userAuthRoutes.DELETE("/sessions/:id", handler.RevokeSession)
 	userRoutes.GET("/search", handler.SearchUsers)
 	userRoutes.GET("/validate/email", handler.ValidateEmail)
 	userRoutes.GET("/validate/username", handler.ValidateUsername)
+	userAuthRoutes.PATCH("/me", handler.UpdateProfile)
+	userRoutes.GET("/:username", handler.GetProfile)
+
+	deckRoutes := a.Router.Group("/decks")
+	deckAuthRoutes := a.Router.Group("/decks", utils.JWTMiddleware())
+	deckRoutes.GET("/popular", handler.GetPopularDecks)
+	deckRoutes.GET("/formats", handler.GetDeckFormats)
+	deckRoutes.GET("/:id", handler.GetDeck)
+	deckRoutes.GET("/:id/archetypes", handler.GetDeckArchetypes)
+	deckRoutes.POST("/import", handler.ImportDeck)
+	deckAuthRoutes.GET("", handler.GetUserDecks)
+	deckAuthRoutes.GET("/affected", handler.GetAffectedDecks)
+	deckAuthRoutes.POST("", handler.CreateDeck)
+	deckAuthRoutes.PUT("/:id", handler.UpdateDeck)
+	deckAuthRoutes.DELETE("/:id", handler.DeleteDeck)
+	deckAuthRoutes.POST("/:id/publish", handler.PublishDeck)
+
+	archetypeRoutes := a.Router.Group("/archetypes")
+	archetypeEditorRoutes := a.Router.Group("/archetypes", utils.JWTMiddleware(), utils.RoleMiddleware(models.RoleEditor))
+	archetypeRoutes.GET("", handler.GetArchetypes)
+	archetypeRoutes.GET("/:sanitizedTitle", handler.GetArchetype)
+	archetypeEditorRoutes.POST("", handler.CreateArchetype)
+	archetypeEditorRoutes.PUT("/:sanitizedTitle", handler.UpdateArchetype)
+	archetypeEditorRoutes.DELETE("/:sanitizedTitle", handler.DeleteArchetype)
+	archetypeEditorRoutes.PUT("/:sanitizedTitle/hidden", handler.HideArchetype)
+
+	adminRoutes := a.Router.Group("/admin", utils.JWTMiddleware(), utils.RoleMiddleware(models.RoleAdmin))
+	adminRoutes.PUT("/users/:id/role", handler.SetUserRole)
+	adminRoutes.GET("/cards/sync", handler.GetCardSyncStatus)
+	adminRoutes.POST("/cards/sync", handler.SyncCards)
 
 	// Start server
 	return a.Router.Start(port)
@@ -13,11 +13,56 @@ type DatabaseConfig struct {
 	Testing  bool   `mapstructure:"testing"`
 }
 
+// JWTKeyConfig describes one key that auth tokens can be signed or verified with.
+// HS256 keys use Secret, or read it from the environment variable named by SecretEnv or from SecretFile.
+// RS256 and EdDSA keys are read from PEM files. A key with only a public key file can still verify tokens,
+// which is useful while rotating keys out.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	Secret         string `mapstructure:"secret"`
+	SecretEnv      string `mapstructure:"secretEnv"`
+	SecretFile     string `mapstructure:"secretFile"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	PublicKeyFile  string `mapstructure:"publicKeyFile"`
+}
+
+type JWTConfig struct {
+	SigningKey string         `mapstructure:"signingKey"`
+	Keys       []JWTKeyConfig `mapstructure:"keys"`
+}
+
+// MailConfig chooses how emails are delivered. The "smtp" driver sends them, the "log" driver writes them to File,
+// or to the log when File is empty. The log driver is only meant for development, since it exposes the links in
+// emails. BaseURL is the frontend address used to build links in emails.
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	File     string `mapstructure:"file"`
+	BaseURL  string `mapstructure:"baseUrl"`
+}
+
+// CardsConfig lists the Data Dragon locales card text is synced in. en_us is always synced.
+// FactionIDs gives the deckcode number of regions that are newer than the deck encoder, keyed by abbreviation.
+// StandardSets lists the sets in the Standard rotation. Standard allows every set when it is empty.
+type CardsConfig struct {
+	Locales      []string       `mapstructure:"locales"`
+	FactionIDs   map[string]int `mapstructure:"factionIds"`
+	StandardSets []int          `mapstructure:"standardSets"`
+}
+
 type Schema struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	API      struct {
 		Token string `mapstructure:"token"`
 	} `mapstructure:"api"`
+	JWT   JWTConfig   `mapstructure:"jwt"`
+	Mail  MailConfig  `mapstructure:"mail"`
+	Cards CardsConfig `mapstructure:"cards"`
 }
 
 var (
@@ -9,17 +9,31 @@ func (c Card) String() string {
 }
 
 func (c Card) GetFaction() Faction {
-	factions := []Faction{DEMACIA, FRELJORD, IONIA, NOXUS, PILTOVERZAUN, SHADOWISLES, BILGEWATER, SHURIMA, MOUNTTARGON, BANDLECITY}
-	if c.Faction >= len(factions) {
-		return UNKNOWN
+	if faction, ok := FactionByID(c.Faction); ok {
+		return faction.Code
 	}
-	return factions[c.Faction]
+	return UNKNOWN
 }
 
+// MinimumVersion is the lowest format version that can encode every card in the deck
+func MinimumVersion(deck Deck) int {
+	version := INITIAL_VERSION
+	for _, card := range deck.Cards {
+		cardVersion := MAX_KNOWN_VERSION
+		if faction, ok := FactionByID(card.Card.Faction); ok {
+			cardVersion = faction.Version
+		}
+		if cardVersion > version {
+			version = cardVersion
+		}
+	}
+	return version
+}
+
+// FactionNumberFromName returns the deckcode number of a region, given its ref or display name, or -1 if it is unknown
 func FactionNumberFromName(reg string) int {
-	factionNames := map[string]int{"Demacia": 0, "Freljord": 1, "Ionia": 2, "Noxus": 3, "Piltover & Zaun": 4, "Shadow Isles": 5, "Bilgewater": 6, "Shurima": 7, "Mount Targon": 9, "Bandle City": 10}
-	if val, ok := factionNames[reg]; ok {
-		return val
+	if faction, ok := FactionByRegion(reg); ok {
+		return faction.ID
 	}
 
 	return -1
@@ -4,8 +4,8 @@ import (
 	"encoding/base32"
 )
 
-func getFormatVersion(params []int) (int, int) {
-	format, version := 1, MAX_KNOWN_VERSION
+func getFormatVersion(deck Deck, params []int) (int, int) {
+	format, version := FORMAT, MinimumVersion(deck)
 	if len(params) > 0 {
 		format = params[0]
 	}
@@ -18,7 +18,7 @@ func getFormatVersion(params []int) (int, int) {
 //dc string - deck string to decode
 //params
 // 			- first param is format - default value 1
-//			- second param is version - default value 1
+//			- second param is version - default value is the lowest version that can encode the deck
 //			- rest is ignored
 func Decode(dc string) (Deck, error) {
 	dc = fixDeckcodeLength(dc)
@@ -26,11 +26,11 @@ func Decode(dc string) (Deck, error) {
 	if err != nil {
 		return Deck{}, err
 	}
-	bs, err = decodeHeader(bs)
-	if err != nil {
+	r := &byteReader{bs: bs}
+	if err := decodeHeader(r); err != nil {
 		return Deck{}, err
 	}
-	deck, err := decodeByteStream(bs)
+	deck, err := decodeByteStream(r)
 	if err != nil {
 		return Deck{}, err
 	}
@@ -38,11 +38,12 @@ func Decode(dc string) (Deck, error) {
 }
 
 func Encode(deck Deck, params ...int) string {
-	format, version := getFormatVersion(params)
+	format, version := getFormatVersion(deck, params)
 	groups := sortDeck(deck)
 	bs := []byte{}
 	bs = append(bs, encodeHeader(format, version)...)
 	bs = append(bs, encodeByteStream(groups)...)
+	bs = append(bs, encodeNOfs(deck)...)
 	dc := removePadding(base32.StdEncoding.EncodeToString(bs))
 	return dc
 }
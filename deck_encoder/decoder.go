@@ -4,6 +4,7 @@ import (
 	"encoding/base32"
 	"encoding/binary"
 	"errors"
+	"fmt"
 )
 
 var (
@@ -11,6 +12,52 @@ var (
 	ErrOldVersion  = errors.New("The provided code requires a higher version of this library; please update.")
 )
 
+// DecodeError describes where in the decoded bytes a deckcode stopped making sense
+type DecodeError struct {
+	Offset int
+	Reason string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s at byte %d: %s", ErrInvalidCode, e.Offset, e.Reason)
+}
+
+// Unwrap lets callers check for ErrInvalidCode with errors.Is
+func (e *DecodeError) Unwrap() error {
+	return ErrInvalidCode
+}
+
+// byteReader reads varints from a deckcode, keeping track of the offset for error messages
+type byteReader struct {
+	bs     []byte
+	offset int
+}
+
+func (r *byteReader) done() bool {
+	return r.offset >= len(r.bs)
+}
+
+func (r *byteReader) fail(format string, args ...interface{}) error {
+	return &DecodeError{Offset: r.offset, Reason: fmt.Sprintf(format, args...)}
+}
+
+// readUvarint reads the next varint, failing if it is truncated, overflows or is larger than max
+func (r *byteReader) readUvarint(field string, max uint64) (int, error) {
+	value, c := binary.Uvarint(r.bs[r.offset:])
+	if c == 0 {
+		return 0, r.fail("%s is truncated", field)
+	}
+	if c < 0 {
+		return 0, r.fail("%s overflows 64 bits", field)
+	}
+	if value > max {
+		return 0, r.fail("%s %d is larger than %d", field, value, max)
+	}
+
+	r.offset += c
+	return int(value), nil
+}
+
 func fixDeckcodeLength(dc string) string {
 	length := len(dc)
 	if length%8 != 0 {
@@ -23,74 +70,138 @@ func fixDeckcodeLength(dc string) string {
 
 //each deckcode starts with 0001 0001 - 4 bits for format(currently only 1) and 4 bits for version(currently only 1)
 // format1 version1 is represented by 00010001 = 17
-func decodeHeader(bs []byte) ([]byte, error) {
-	byteFormatVersion, c := binary.Uvarint(bs)
-	version := byteFormatVersion & 0xF
-	if c != 1 {
-		return bs, ErrInvalidCode
+func decodeHeader(r *byteReader) error {
+	if r.done() {
+		return r.fail("missing header")
+	}
+
+	byteFormatVersion := r.bs[r.offset]
+	if byteFormatVersion&0x80 != 0 {
+		return r.fail("header is longer than one byte")
 	}
-	if int(version) > MAX_KNOWN_VERSION {
-		return bs, ErrOldVersion
+	if int(byteFormatVersion&0xF) > MAX_KNOWN_VERSION {
+		return ErrOldVersion
 	}
-	bs = bs[c:]
-	return bs, nil
+
+	r.offset++
+	return nil
 }
 
-func decodeByteStream(bs []byte) (Deck, error) {
+func decodeByteStream(r *byteReader) (Deck, error) {
 	var deck Deck
-	for len(bs) > 0 {
-		for i := 0; i < MAX_CARD_COUNT; i++ {
-			var err error
-			var cards []CardInDeck
-			bs, cards, err = decodeSetFactionCombinations(bs, MAX_CARD_COUNT-i)
-			if err != nil {
-				return Deck{}, err
-			}
-			deck.Cards = append(deck.Cards, cards...)
+	if r.done() {
+		return deck, nil
+	}
+
+	for i := 0; i < MAX_CARD_COUNT; i++ {
+		cards, err := decodeSetFactionCombinations(r, MAX_CARD_COUNT-i)
+		if err != nil {
+			return Deck{}, err
+		}
+		deck.Cards = append(deck.Cards, cards...)
+		if len(deck.Cards) > MAX_UNIQUE_CARDS {
+			return Deck{}, r.fail("deck has more than %d unique cards", MAX_UNIQUE_CARDS)
 		}
-		if len(bs) != 0 {
-			return Deck{}, ErrInvalidCode
+	}
+
+	for !r.done() {
+		card, err := decodeNOf(r)
+		if err != nil {
+			return Deck{}, err
+		}
+		deck.Cards = append(deck.Cards, card)
+		if len(deck.Cards) > MAX_UNIQUE_CARDS {
+			return Deck{}, r.fail("deck has more than %d unique cards", MAX_UNIQUE_CARDS)
 		}
 	}
+
 	return deck, nil
 }
 
-func decodeSetFactionCombinations(bs []byte, count int) ([]byte, []CardInDeck, error) {
+// decodeNOf reads one card from the trailing section, which holds cards with more than MAX_CARD_COUNT copies
+func decodeNOf(r *byteReader) (CardInDeck, error) {
+	count, err := r.readUvarint("copy count", MAX_COPY_COUNT)
+	if err != nil {
+		return CardInDeck{}, err
+	}
+	if count <= MAX_CARD_COUNT {
+		return CardInDeck{}, r.fail("copy count %d should be in the %d-of groups", count, count)
+	}
+
+	set, err := r.readUvarint("set", MAX_SET_NUMBER)
+	if err != nil {
+		return CardInDeck{}, err
+	}
+	faction, err := r.readUvarint("faction", MAX_FACTION_ID)
+	if err != nil {
+		return CardInDeck{}, err
+	}
+	cardNumber, err := r.readUvarint("card number", MAX_CARD_NUMBER)
+	if err != nil {
+		return CardInDeck{}, err
+	}
+
+	return CardInDeck{
+		Card: Card{
+			Set:     set,
+			Faction: faction,
+			Number:  cardNumber,
+		},
+		Count: count,
+	}, nil
+}
+
+func decodeSetFactionCombinations(r *byteReader, count int) ([]CardInDeck, error) {
 	var returnCards []CardInDeck
-	combinationCount, c := binary.Uvarint(bs)
-	bs = bs[c:]
-	for j := 0; j < int(combinationCount); j++ {
-		var cards []CardInDeck
-		var err error
-		bs, cards, err = decodeSetFactionCombinationCards(bs, count)
+	combinationCount, err := r.readUvarint(fmt.Sprintf("group count for %d-ofs", count), MAX_GROUP_COUNT)
+	if err != nil {
+		return nil, err
+	}
+
+	for j := 0; j < combinationCount; j++ {
+		cards, err := decodeSetFactionCombinationCards(r, count)
 		if err != nil {
-			return []byte{}, []CardInDeck{}, err
+			return nil, err
 		}
 		returnCards = append(returnCards, cards...)
 	}
-	return bs, returnCards, nil
+	return returnCards, nil
 }
 
-func decodeSetFactionCombinationCards(bs []byte, count int) ([]byte, []CardInDeck, error) {
-	var cards []CardInDeck
-	countOfUniqueCards, c := binary.Uvarint(bs)
-	bs = bs[c:]
-	set, c := binary.Uvarint(bs)
-	bs = bs[c:]
-	faction, c := binary.Uvarint(bs)
-	bs = bs[c:]
-	for i := 0; i < int(countOfUniqueCards); i++ {
-		cardNumber, c := binary.Uvarint(bs)
-		bs = bs[c:]
+func decodeSetFactionCombinationCards(r *byteReader, count int) ([]CardInDeck, error) {
+	countOfUniqueCards, err := r.readUvarint("card count", MAX_UNIQUE_CARDS)
+	if err != nil {
+		return nil, err
+	}
+	if countOfUniqueCards == 0 {
+		return nil, r.fail("group has no cards")
+	}
+
+	set, err := r.readUvarint("set", MAX_SET_NUMBER)
+	if err != nil {
+		return nil, err
+	}
+	faction, err := r.readUvarint("faction", MAX_FACTION_ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]CardInDeck, 0, countOfUniqueCards)
+	for i := 0; i < countOfUniqueCards; i++ {
+		cardNumber, err := r.readUvarint("card number", MAX_CARD_NUMBER)
+		if err != nil {
+			return nil, err
+		}
+
 		card := CardInDeck{
 			Card: Card{
-				Set:     int(set),
-				Faction: int(faction),
-				Number:  int(cardNumber),
+				Set:     set,
+				Faction: faction,
+				Number:  cardNumber,
 			},
 			Count: count,
 		}
 		cards = append(cards, card)
 	}
-	return bs, cards, nil
+	return cards, nil
 }
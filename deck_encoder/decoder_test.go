@@ -0,0 +1,162 @@
+package deck_encoder
+
+import (
+	"bufio"
+	"encoding/base32"
+	"errors"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type corpusEntry struct {
+	valid bool
+	code  string
+}
+
+func readCorpus(t *testing.T) []corpusEntry {
+	file, err := os.Open("testdata/decode_corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var entries []corpusEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+		entry := corpusEntry{valid: parts[0] == "ok"}
+		if len(parts) > 1 {
+			entry.code = parts[1]
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestDecodeCorpus(t *testing.T) {
+	for _, entry := range readCorpus(t) {
+		_, err := Decode(entry.code)
+		if entry.valid {
+			assert.Nil(t, err, entry.code)
+		} else {
+			assert.NotNil(t, err, entry.code)
+		}
+	}
+}
+
+func TestDecodeErrorOffset(t *testing.T) {
+	_, err := Decode("CQAQCAIB5ADQAAA")
+
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, 5, decodeErr.Offset)
+	assert.True(t, errors.Is(err, ErrInvalidCode))
+	assert.Equal(t, "deckcode invalid at byte 5: card number 1000 is larger than 999", err.Error())
+
+	_, err = Decode("D4AAAAA")
+	assert.Equal(t, ErrOldVersion, err)
+}
+
+// TestDecodeMutations truncates and corrupts every valid corpus code, checking the decoder only ever returns errors
+func TestDecodeMutations(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, entry := range readCorpus(t) {
+		if !entry.valid {
+			continue
+		}
+
+		bs, err := base32.StdEncoding.DecodeString(fixDeckcodeLength(entry.code))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range bs {
+			decodeMutation(t, bs[:i])
+
+			for j := 0; j < 50; j++ {
+				mutated := append([]byte{}, bs...)
+				mutated[i] = byte(random.Intn(256))
+				decodeMutation(t, mutated)
+			}
+		}
+
+		for j := 0; j < 200; j++ {
+			extended := append(append([]byte{}, bs...), byte(random.Intn(256)))
+			decodeMutation(t, extended)
+		}
+	}
+}
+
+func decodeMutation(t *testing.T, bs []byte) {
+	code := removePadding(base32.StdEncoding.EncodeToString(bs))
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("decoding %s panicked: %v", code, r)
+		}
+	}()
+
+	deck, err := Decode(code)
+	if err != nil {
+		return
+	}
+
+	assert.True(t, len(deck.Cards) <= MAX_UNIQUE_CARDS, code)
+	for _, card := range deck.Cards {
+		assert.True(t, card.Card.Number <= MAX_CARD_NUMBER, code)
+		assert.True(t, card.Card.Set <= MAX_SET_NUMBER, code)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	deck := Deck{
+		Cards: []CardInDeck{
+			{Card: Card{Set: 1, Faction: 1, Number: 24}, Count: 3},
+			{Card: Card{Set: 2, Faction: 6, Number: 8}, Count: 2},
+			{Card: Card{Set: 1, Faction: 0, Number: 999}, Count: 1},
+			{Card: Card{Set: 6, Faction: 12, Number: 1}, Count: 4},
+			{Card: Card{Set: 1, Faction: 2, Number: 12}, Count: 6},
+		},
+	}
+
+	code := Encode(deck)
+	decoded, err := Decode(code)
+
+	assert.Nil(t, err)
+	assert.Equal(t, deck.Cards[:3], decoded.Cards[:3])
+	assert.ElementsMatch(t, deck.Cards[3:], decoded.Cards[3:])
+	assert.Equal(t, "CU", code[:2])
+}
+
+func TestMinimumVersion(t *testing.T) {
+	deck := Deck{Cards: []CardInDeck{{Card: Card{Set: 1, Faction: 1, Number: 24}, Count: 3}}}
+	assert.Equal(t, 1, MinimumVersion(deck))
+	assert.Equal(t, "CE", Encode(deck)[:2])
+	assert.Equal(t, "CQ", Encode(deck, 1, 4)[:2])
+
+	deck.Cards = append(deck.Cards, CardInDeck{Card: Card{Set: 2, Faction: 9, Number: 1}, Count: 1})
+	assert.Equal(t, 2, MinimumVersion(deck))
+
+	deck.Cards = append(deck.Cards, CardInDeck{Card: Card{Set: 4, Faction: 10, Number: 1}, Count: 1})
+	assert.Equal(t, 4, MinimumVersion(deck))
+
+	assert.Equal(t, INITIAL_VERSION, MinimumVersion(Deck{}))
+}
+
+func TestGetFaction(t *testing.T) {
+	assert.Equal(t, MOUNTTARGON, Card{Faction: 9}.GetFaction())
+	assert.Equal(t, BANDLECITY, Card{Faction: 10}.GetFaction())
+	assert.Equal(t, RUNETERRA, Card{Faction: 12}.GetFaction())
+	assert.Equal(t, UNKNOWN, Card{Faction: 8}.GetFaction())
+}
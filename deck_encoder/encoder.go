@@ -81,9 +81,34 @@ func encodeSetFactionCombinationCards(cards []Card) []byte {
 	return bs
 }
 
+// encodeNOfs writes the trailing section for cards with more than MAX_CARD_COUNT copies.
+// Each card is written on its own as count, set, faction and number.
+func encodeNOfs(deck Deck) []byte {
+	nOfs := []CardInDeck{}
+	for i := range deck.Cards {
+		if deck.Cards[i].Count > MAX_CARD_COUNT {
+			nOfs = append(nOfs, deck.Cards[i])
+		}
+	}
+	sort.Slice(nOfs, func(i, j int) bool { return nOfs[i].Card.String() < nOfs[j].Card.String() })
+
+	dummy := make([]byte, unsafe.Sizeof(uint64(0)))
+	bs := []byte{}
+	for _, card := range nOfs {
+		for _, value := range []int{card.Count, card.Card.Set, card.Card.Faction, card.Card.Number} {
+			c := binary.PutUvarint(dummy, uint64(value))
+			bs = append(bs, dummy[:c]...)
+		}
+	}
+	return bs
+}
+
 func sortDeck(deck Deck) []group_t {
 	groups := []group_t{}
 	for i := range deck.Cards {
+		if deck.Cards[i].Count < 1 || deck.Cards[i].Count > MAX_CARD_COUNT {
+			continue
+		}
 		setFaction := fmt.Sprintf("%02d%s", deck.Cards[i].Card.Set, deck.Cards[i].Card.GetFaction())
 		if group, contains := groupsContains(groups, deck.Cards[i].Count, setFaction); contains {
 			groups[group].cards = append(groups[group].cards, deck.Cards[i].Card)
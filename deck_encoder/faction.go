@@ -0,0 +1,103 @@
+package deck_encoder
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FactionInfo ties together the ways a faction is identified: its number in deckcodes, the code in card codes,
+// and the region ref and display name used by Data Dragon. Aliases are older names still found in stored data.
+type FactionInfo struct {
+	ID      int
+	Code    Faction
+	Ref     string
+	Name    string
+	Aliases []string
+	Version int
+}
+
+var (
+	factionLock sync.RWMutex
+	factionList = []FactionInfo{
+		{ID: 0, Code: DEMACIA, Ref: "Demacia", Name: "Demacia", Version: 1},
+		{ID: 1, Code: FRELJORD, Ref: "Freljord", Name: "Freljord", Version: 1},
+		{ID: 2, Code: IONIA, Ref: "Ionia", Name: "Ionia", Version: 1},
+		{ID: 3, Code: NOXUS, Ref: "Noxus", Name: "Noxus", Version: 1},
+		{ID: 4, Code: PILTOVERZAUN, Ref: "PiltoverZaun", Name: "Piltover & Zaun", Version: 1},
+		{ID: 5, Code: SHADOWISLES, Ref: "ShadowIsles", Name: "Shadow Isles", Version: 1},
+		{ID: 6, Code: BILGEWATER, Ref: "Bilgewater", Name: "Bilgewater", Version: 2},
+		{ID: 7, Code: SHURIMA, Ref: "Shurima", Name: "Shurima", Version: 3},
+		{ID: 9, Code: MOUNTTARGON, Ref: "Targon", Name: "Targon", Aliases: []string{"Mount Targon", "MtTargon"}, Version: 2},
+		{ID: 10, Code: BANDLECITY, Ref: "BandleCity", Name: "Bandle City", Version: 4},
+		{ID: 12, Code: RUNETERRA, Ref: "Runeterra", Name: "Runeterra", Version: 5},
+	}
+)
+
+// RegisterFaction adds a faction, or replaces the one with the same ID
+func RegisterFaction(faction FactionInfo) {
+	factionLock.Lock()
+	defer factionLock.Unlock()
+
+	faction.Code = Faction(strings.ToUpper(string(faction.Code)))
+	if faction.Version == 0 {
+		faction.Version = MAX_KNOWN_VERSION
+	}
+
+	for i := range factionList {
+		if factionList[i].ID == faction.ID {
+			factionList[i] = faction
+			return
+		}
+	}
+
+	factionList = append(factionList, faction)
+	sort.Slice(factionList, func(i, j int) bool { return factionList[i].ID < factionList[j].ID })
+}
+
+// Factions lists every known faction ordered by ID
+func Factions() []FactionInfo {
+	factionLock.RLock()
+	defer factionLock.RUnlock()
+
+	return append([]FactionInfo{}, factionList...)
+}
+
+func findFaction(matches func(FactionInfo) bool) (FactionInfo, bool) {
+	factionLock.RLock()
+	defer factionLock.RUnlock()
+
+	for _, faction := range factionList {
+		if matches(faction) {
+			return faction, true
+		}
+	}
+	return FactionInfo{}, false
+}
+
+func FactionByID(id int) (FactionInfo, bool) {
+	return findFaction(func(f FactionInfo) bool { return f.ID == id })
+}
+
+// FactionByCode finds a faction by the two letter code used in card codes, such as "DE"
+func FactionByCode(code string) (FactionInfo, bool) {
+	return findFaction(func(f FactionInfo) bool { return strings.EqualFold(string(f.Code), code) })
+}
+
+// FactionByRegion finds a faction by its Data Dragon region ref, display name or one of its aliases
+func FactionByRegion(region string) (FactionInfo, bool) {
+	return findFaction(func(f FactionInfo) bool { return f.HasName(region) })
+}
+
+// HasName reports whether the name is the faction's ref, display name or an alias
+func (f FactionInfo) HasName(name string) bool {
+	if strings.EqualFold(f.Ref, name) || strings.EqualFold(f.Name, name) {
+		return true
+	}
+	for _, alias := range f.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
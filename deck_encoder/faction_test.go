@@ -0,0 +1,48 @@
+package deck_encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactionLookups(t *testing.T) {
+	faction, ok := FactionByCode("pz")
+	assert.True(t, ok)
+	assert.Equal(t, 4, faction.ID)
+
+	faction, ok = FactionByRegion("PiltoverZaun")
+	assert.True(t, ok)
+	assert.Equal(t, PILTOVERZAUN, faction.Code)
+
+	faction, ok = FactionByRegion("shadow isles")
+	assert.True(t, ok)
+	assert.Equal(t, 5, faction.ID)
+
+	assert.Equal(t, 9, FactionNumberFromName("Targon"))
+	assert.Equal(t, 9, FactionNumberFromName("Mount Targon"))
+	assert.Equal(t, -1, FactionNumberFromName("Void"))
+
+	_, ok = FactionByID(8)
+	assert.False(t, ok)
+}
+
+func TestRegisterFaction(t *testing.T) {
+	RegisterFaction(FactionInfo{ID: 30, Code: "vo", Ref: "Void", Name: "The Void"})
+
+	faction, ok := FactionByCode("VO")
+	assert.True(t, ok)
+	assert.Equal(t, 30, faction.ID)
+	assert.Equal(t, MAX_KNOWN_VERSION, faction.Version)
+	assert.Equal(t, 30, FactionNumberFromName("The Void"))
+	assert.Equal(t, "01VO001", Card{Set: 1, Faction: 30, Number: 1}.String())
+
+	factions := Factions()
+	assert.Equal(t, 30, factions[len(factions)-1].ID)
+
+	RegisterFaction(FactionInfo{ID: 30, Code: "VO", Ref: "Void", Name: "Void", Version: 5})
+
+	faction, _ = FactionByID(30)
+	assert.Equal(t, "Void", faction.Name)
+	assert.Equal(t, len(factions), len(Factions()))
+}
@@ -29,9 +29,25 @@ const (
 	SHURIMA      Faction = "SH"
 	MOUNTTARGON  Faction = "MT"
 	BANDLECITY   Faction = "BC"
+	RUNETERRA    Faction = "RU"
 	UNKNOWN      Faction = "XX"
 )
 
+// MAX_CARD_COUNT is the highest count with its own group section. Cards with more copies go in the trailing section.
 const MAX_CARD_COUNT = 3
 
-const MAX_KNOWN_VERSION = 4
+// Limits the decoder enforces, so malformed codes can't produce absurd decks
+const (
+	MAX_SET_NUMBER   = 99
+	MAX_FACTION_ID   = 31
+	MAX_CARD_NUMBER  = 999
+	MAX_GROUP_COUNT  = 64
+	MAX_UNIQUE_CARDS = 100
+	MAX_COPY_COUNT   = 99
+)
+
+const FORMAT = 1
+
+const INITIAL_VERSION = 1
+
+const MAX_KNOWN_VERSION = 5
@@ -3,9 +3,9 @@ module gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go
 go 1.13
 
 require (
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible // indirect
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/go-playground/universal-translator v0.17.0 // indirect
-	github.com/go-playground/validator v9.31.0+incompatible // indirect
+	github.com/go-playground/validator v9.31.0+incompatible
 	github.com/google/go-cmp v0.5.5
 	github.com/labstack/echo v3.3.10+incompatible
 	github.com/labstack/gommon v0.3.0 // indirect
@@ -14,7 +14,7 @@ require (
 	github.com/robfig/cron v1.2.0
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.7.0
-	github.com/teris-io/shortid v0.0.0-20201117134242-e59966efd125 // indirect
+	github.com/teris-io/shortid v0.0.0-20201117134242-e59966efd125
 	github.com/valyala/fasttemplate v1.2.1 // indirect
 	go.mongodb.org/mongo-driver v1.4.6
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/mailer"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	passwordResetLifetime     = time.Hour
+	emailVerificationLifetime = 24 * time.Hour
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" bson:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" bson:"token" validate:"required"`
+	Password string `json:"password" bson:"password" validate:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" bson:"token" validate:"required"`
+}
+
+func actionLink(path, token string) string {
+	return config.Config.Mail.BaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// issueActionToken stores a new single-use token for the user and returns the signed form that goes in the email link
+func issueActionToken(user *models.User, purpose models.TokenPurpose, lifetime time.Duration) (string, error) {
+	userToken, err := models.UserTokens.CreateToken(user.UserID(), purpose, user.Email, lifetime)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.CreateActionToken(*userToken)
+}
+
+// redeemActionToken checks the signed token and uses up its stored record
+func redeemActionToken(token string, purpose models.TokenPurpose) (*models.UserToken, error) {
+	tokenID, err := utils.DecodeActionToken(token, purpose)
+	if err != nil {
+		return nil, types.BadRequest("Invalid or expired token")
+	}
+
+	userToken, err := models.UserTokens.ConsumeToken(tokenID, purpose)
+	if err == mongo.ErrNoDocuments {
+		return nil, types.BadRequest("Invalid or expired token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return userToken, nil
+}
+
+func sendVerificationEmail(user *models.User) error {
+	token, err := issueActionToken(user, models.PurposeEmailVerification, emailVerificationLifetime)
+	if err != nil {
+		return err
+	}
+
+	link := actionLink("/verify-email", token)
+	return mailer.Default.Send(mailer.EmailVerificationMessage(user.Email, user.Username, link))
+}
+
+func sendPasswordResetEmail(user *models.User) error {
+	token, err := issueActionToken(user, models.PurposePasswordReset, passwordResetLifetime)
+	if err != nil {
+		return err
+	}
+
+	link := actionLink("/reset-password", token)
+	return mailer.Default.Send(mailer.PasswordResetMessage(user.Email, user.Username, link))
+}
+
+func ForgotPassword(c echo.Context) error {
+	r := new(ForgotPasswordRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	// Always succeed so the endpoint can't be used to find out which emails have accounts. Failures only happen
+	// for registered emails, so they are logged instead of returned.
+	user, err := models.Users.GetUserByEmail(r.Email)
+	if err != nil {
+		return c.JSON(http.StatusOK, true)
+	}
+
+	logMailError(sendPasswordResetEmail(user))
+
+	return c.JSON(http.StatusOK, true)
+}
+
+func ResetPassword(c echo.Context) error {
+	r := new(ResetPasswordRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	userToken, err := redeemActionToken(r.Token, models.PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if _, err := models.Users.SetPassword(userToken.UserID, r.Password); err != nil {
+		return err
+	}
+
+	if _, err := models.Sessions.RevokeAllSessions(userToken.UserID); err != nil {
+		return err
+	}
+	utils.ClearAuthCookies(c)
+
+	return c.JSON(http.StatusOK, true)
+}
+
+func RequestEmailVerification(c echo.Context) error {
+	claims, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := models.Users.GetUserById(claims.UserID())
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return types.BadRequest("Your email address is already verified")
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, true)
+}
+
+func VerifyEmail(c echo.Context) error {
+	r := new(VerifyEmailRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	userToken, err := redeemActionToken(r.Token, models.PurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	if _, err := models.Users.VerifyEmail(userToken.UserID, userToken.Email); err != nil {
+		return types.BadRequest(err.Error())
+	}
+
+	return c.JSON(http.StatusOK, true)
+}
+
+func logMailError(err error) {
+	if err != nil {
+		log.Printf("Could not send email: %s", err)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SetUserRoleRequest struct {
+	Role string `json:"role" bson:"role" validate:"required"`
+}
+
+func SetUserRole(c echo.Context) error {
+	admin, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	r := new(SetUserRoleRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	role, err := models.ParseRole(r.Role)
+	if err != nil {
+		return types.BadRequest(err.Error())
+	}
+
+	id := c.Param("id")
+	if id == admin.UserID() {
+		return types.BadRequest("You cannot change your own role")
+	}
+
+	user, err := models.Users.SetUserRole(id, role)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return types.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// The role is kept in the user's tokens, so sign them out everywhere to make the change apply straight away
+	if _, err := models.Sessions.RevokeAllSessions(user.UserID()); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user.Public())
+}
+
+func GetCardSyncStatus(c echo.Context) error {
+	return c.JSON(http.StatusOK, utils.DefaultCardSync.Status())
+}
+
+// SyncCards starts a card sync in the background. Its progress can be followed with GetCardSyncStatus.
+func SyncCards(c echo.Context) error {
+	if err := utils.DefaultCardSync.StartUpdate(); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, utils.DefaultCardSync.Status())
+}
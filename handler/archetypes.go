@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ArchetypeRequest struct {
+	Title       string   `json:"title" bson:"title" validate:"required,max=100"`
+	Decks       []string `json:"decks" bson:"decks" validate:"required,min=1"`
+	Description string   `json:"description" bson:"description"`
+	Meta        string   `json:"meta" bson:"meta"`
+	Background  string   `json:"background" bson:"background"`
+}
+
+func (r ArchetypeRequest) applyTo(archetype *models.Archetype) {
+	archetype.Title = r.Title
+	archetype.Decks = r.Decks
+	archetype.Description = r.Description
+	archetype.Meta = r.Meta
+	archetype.Background = r.Background
+}
+
+type HideArchetypeRequest struct {
+	Hidden bool `json:"hidden" bson:"hidden"`
+}
+
+func getArchetype(sanitizedTitle string) (*models.Archetype, error) {
+	archetype, err := models.Archetypes.GetArchetypeBySanitizedTitle(sanitizedTitle)
+	if err == mongo.ErrNoDocuments {
+		return nil, types.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return archetype, nil
+}
+
+// checkTitleAvailable makes sure no other archetype already uses the archetype's sanitized title
+func checkTitleAvailable(archetype *models.Archetype) error {
+	existing, err := models.Archetypes.GetArchetypeBySanitizedTitle(archetype.SanitizedTitle)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != archetype.ID {
+		return types.Conflict("An archetype with that title already exists")
+	}
+
+	return nil
+}
+
+func GetArchetypes(c echo.Context) error {
+	archetypes, err := models.Archetypes.GetArchetypes()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, archetypes)
+}
+
+func GetArchetype(c echo.Context) error {
+	archetype, err := getArchetype(c.Param("sanitizedTitle"))
+	if err != nil {
+		return err
+	}
+
+	if archetype.Hidden {
+		return types.ErrNotFound
+	}
+
+	populated, err := archetype.PopulateDecks()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, populated)
+}
+
+func GetDeckArchetypes(c echo.Context) error {
+	archetypes, err := models.Archetypes.GetDeckArchetypes(c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, archetypes)
+}
+
+func GetCardArchetypes(c echo.Context) error {
+	archetypes, err := models.Archetypes.GetCardArchetypes(c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, archetypes)
+}
+
+func CreateArchetype(c echo.Context) error {
+	r := new(ArchetypeRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	archetype := models.Archetype{}
+	r.applyTo(&archetype)
+
+	if err := archetype.CalculateDetails(); err != nil {
+		return err
+	}
+	if err := checkTitleAvailable(&archetype); err != nil {
+		return err
+	}
+
+	saved, err := models.Archetypes.SaveArchetype(archetype)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, saved)
+}
+
+func UpdateArchetype(c echo.Context) error {
+	archetype, err := getArchetype(c.Param("sanitizedTitle"))
+	if err != nil {
+		return err
+	}
+
+	r := new(ArchetypeRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	r.applyTo(archetype)
+
+	if err := archetype.CalculateDetails(); err != nil {
+		return err
+	}
+	if err := checkTitleAvailable(archetype); err != nil {
+		return err
+	}
+
+	updated, err := models.Archetypes.UpdateArchetype(*archetype)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+func DeleteArchetype(c echo.Context) error {
+	archetype, err := getArchetype(c.Param("sanitizedTitle"))
+	if err != nil {
+		return err
+	}
+
+	deleted, err := models.Archetypes.DeleteArchetype(archetype.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deleted)
+}
+
+func HideArchetype(c echo.Context) error {
+	archetype, err := getArchetype(c.Param("sanitizedTitle"))
+	if err != nil {
+		return err
+	}
+
+	r := new(HideArchetypeRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+
+	updated, err := models.Archetypes.SetArchetypeHidden(archetype.ID, r.Hidden)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
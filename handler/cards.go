@@ -2,15 +2,85 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
 )
 
+type CardsRequest struct {
+	Regions     []string `query:"regions" validate:"max=20"`
+	MinCost     int      `query:"minCost" validate:"min=0"`
+	MaxCost     int      `query:"maxCost" validate:"min=0"`
+	Rarities    []string `query:"rarities" validate:"max=10"`
+	Types       []string `query:"types" validate:"max=10"`
+	Supertypes  []string `query:"supertypes" validate:"max=10"`
+	Keywords    []string `query:"keywords" validate:"max=10"`
+	Sets        []int    `query:"sets" validate:"max=20"`
+	Collectible bool     `query:"collectible"`
+	Search      string   `query:"search" validate:"max=100"`
+	Sorting     string   `query:"sorting" validate:"omitempty,oneof=code name cost attack health set"`
+	SortAsc     int      `query:"sortAsc" validate:"oneof=-1 0 1"`
+	Page        int      `query:"page" validate:"min=0,max=10000"`
+	Limit       int      `query:"limit" validate:"min=0,max=500"`
+}
+
+// toQuery builds the card query. Cost and collectible only filter when they are given, since their zero values are meaningful.
+func (r CardsRequest) toQuery(c echo.Context) models.CardQuery {
+	query := models.CardQuery{
+		Regions:    r.Regions,
+		Rarities:   r.Rarities,
+		Types:      r.Types,
+		Supertypes: r.Supertypes,
+		Keywords:   r.Keywords,
+		Sets:       r.Sets,
+		Search:     r.Search,
+		Sorting:    r.Sorting,
+		SortAsc:    r.SortAsc,
+		Page:       r.Page,
+		Limit:      r.Limit,
+	}
+
+	if len(c.QueryParam("minCost")) > 0 {
+		query.MinCost = &r.MinCost
+	}
+	if len(c.QueryParam("maxCost")) > 0 {
+		query.MaxCost = &r.MaxCost
+	}
+	if len(c.QueryParam("collectible")) > 0 {
+		query.Collectible = &r.Collectible
+	}
+
+	return query
+}
+
+// requestLocale is the locale card text should be returned in, from ?locale= or the Accept-Language header
+func requestLocale(c echo.Context) string {
+	c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+
+	return utils.ResolveLocale(c.QueryParam("locale"), c.Request().Header.Get("Accept-Language"), config.Config.Cards.Locales)
+}
+
+// GetCards returns the matching cards, with the number of matches before paging in the X-Total-Count header
 func GetCards(c echo.Context) error {
-	data := models.Cards.GetAll()
+	r := new(CardsRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
 
-	return c.JSON(http.StatusOK, data)
+	query := r.toQuery(c)
+	query.Locale = requestLocale(c)
+
+	cards, total := models.Cards.SearchCards(query)
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	return c.JSON(http.StatusOK, cards)
 }
 
 func GetCard(c echo.Context) error {
@@ -18,8 +88,22 @@ func GetCard(c echo.Context) error {
 	data := models.Cards.GetCard(id)
 
 	if data == nil {
-		return echo.ErrNotFound
+		return types.ErrNotFound
+	}
+
+	return c.JSON(http.StatusOK, data.Localize(requestLocale(c)))
+}
+
+func GetCardHistory(c echo.Context) error {
+	id := c.Param("id")
+	if models.Cards.GetCard(id) == nil {
+		return types.ErrNotFound
+	}
+
+	history, err := models.CardRevisions.GetCardHistory(id)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, history)
 }
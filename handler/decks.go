@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type DeckRequest struct {
+	Title   string                `json:"title" bson:"title" validate:"required,max=100"`
+	Cards   []models.CardQuantity `json:"cards" bson:"cards"`
+	Guide   string                `json:"guide" bson:"guide"`
+	Sandbox bool                  `json:"sandbox" bson:"sandbox"`
+	Format  string                `json:"format" bson:"format"`
+}
+
+func (r DeckRequest) applyTo(deck *models.Deck) {
+	deck.Title = r.Title
+	deck.Cards = r.Cards
+	deck.Guide = r.Guide
+	deck.Sandbox = r.Sandbox
+	deck.Format = r.Format
+}
+
+// prepareDeck validates the deck and works out the fields that are derived from its cards.
+// Every broken rule is returned in the error's details.
+func prepareDeck(deck *models.Deck, publish bool) error {
+	format, ok := models.DeckFormatByID(deck.Format)
+	if !ok {
+		return types.BadRequest("Unknown deck format " + deck.Format)
+	}
+
+	if violations := deck.Violations(format, publish, deck.Sandbox); len(violations) > 0 {
+		return models.NewDeckRulesError(violations)
+	}
+
+	regions, err := deck.CalculateRegions()
+	if err != nil {
+		return err
+	}
+
+	deck.Regions = regions
+	deck.DeckCode = deck.StableCode()
+
+	return nil
+}
+
+func getDeck(id string) (*models.Deck, error) {
+	deck, err := models.Decks.GetDeck(id)
+	if err == mongo.ErrNoDocuments {
+		return nil, types.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deck.Deleted {
+		return nil, types.ErrNotFound
+	}
+
+	return deck, nil
+}
+
+func getOwnedDeck(c echo.Context) (*models.Deck, error) {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+
+	deck, err := getDeck(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if deck.Owner != user.UserID() {
+		return nil, types.Forbidden("You do not own this deck")
+	}
+
+	return deck, nil
+}
+
+func requestUser(c echo.Context) *models.PublicUser {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return nil
+	}
+
+	return user
+}
+
+func CreateDeck(c echo.Context) error {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	r := new(DeckRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	deck := models.Deck{
+		Owner:         user.UserID(),
+		OwnerUsername: user.Username,
+		DateCreated:   time.Now(),
+		DateUpdated:   time.Now(),
+	}
+	r.applyTo(&deck)
+
+	if err := prepareDeck(&deck, false); err != nil {
+		return err
+	}
+
+	saved, err := models.Decks.SaveDeck(deck)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, saved)
+}
+
+func GetDeck(c echo.Context) error {
+	deck, err := getDeck(c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	if !deck.Published {
+		user := requestUser(c)
+		if user == nil || user.UserID() != deck.Owner {
+			return types.ErrNotFound
+		}
+	}
+
+	return c.JSON(http.StatusOK, deck)
+}
+
+func GetUserDecks(c echo.Context) error {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	decks, err := models.Decks.GetDecksByOwnerID(user.UserID())
+	if err != nil {
+		return err
+	}
+
+	activeDecks := make([]*models.Deck, 0)
+	for _, deck := range decks {
+		if !deck.Deleted {
+			activeDecks = append(activeDecks, deck)
+		}
+	}
+
+	return c.JSON(http.StatusOK, activeDecks)
+}
+
+func UpdateDeck(c echo.Context) error {
+	deck, err := getOwnedDeck(c)
+	if err != nil {
+		return err
+	}
+
+	r := new(DeckRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	r.applyTo(deck)
+	deck.DateUpdated = time.Now()
+
+	// Updating the deck counts as the owner having reviewed the patch that affected it
+	if deck.Badge.IsPatchAffected() {
+		deck.Badge = models.DeckBadge{}
+	}
+
+	if err := prepareDeck(deck, deck.Published); err != nil {
+		return err
+	}
+
+	updated, err := models.Decks.UpdateDeck(*deck)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+func DeleteDeck(c echo.Context) error {
+	deck, err := getOwnedDeck(c)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := models.Decks.DeleteDeck(deck.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deleted)
+}
+
+func PublishDeck(c echo.Context) error {
+	deck, err := getOwnedDeck(c)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareDeck(deck, true); err != nil {
+		return err
+	}
+
+	if _, err := models.Decks.UpdateDeck(*deck); err != nil {
+		return err
+	}
+
+	published, err := models.Decks.PublishDeck(deck.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, published)
+}
+
+func GetAffectedDecks(c echo.Context) error {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	affected, err := models.AffectedDecks.GetOwnerAffectedDecks(user.UserID())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, affected)
+}
+
+func GetDeckFormats(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.DeckFormats())
+}
+
+type ImportDeckRequest struct {
+	DeckCode string `json:"deckCode" bson:"deckCode" validate:"required"`
+}
+
+type ImportDeckResponse struct {
+	Deck         *models.Deck           `json:"deck"`
+	UnknownCards []string               `json:"unknownCards"`
+	Violations   []models.DeckViolation `json:"violations"`
+}
+
+func ImportDeck(c echo.Context) error {
+	r := new(ImportDeckRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	deck, unknownCards, err := models.DeckFromCode(r.DeckCode)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ImportDeckResponse{
+		Deck:         deck,
+		UnknownCards: unknownCards,
+		Violations:   deck.Violations(deck.DeckFormat(), false, false),
+	})
+}
+
+const defaultPopularDecksLimit = 20
+
+type PopularDecksRequest struct {
+	Cards          []string `query:"cards" validate:"max=40"`
+	Search         string   `query:"search" validate:"max=100"`
+	Regions        []string `query:"regions" validate:"max=10"`
+	Types          []string `query:"types" validate:"max=10"`
+	FeaturedPlayer bool     `query:"featuredPlayer"`
+	Sorting        string   `query:"sorting" validate:"omitempty,oneof=popularity pageViews datePublished dateUpdated title"`
+	SortAsc        int      `query:"sortAsc" validate:"oneof=-1 0 1"`
+	Page           int      `query:"page" validate:"min=0,max=10000"`
+	Limit          int      `query:"limit" validate:"min=0,max=50"`
+}
+
+func (r PopularDecksRequest) toQuery() models.SearchPopularDecksQuery {
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultPopularDecksLimit
+	}
+
+	return models.SearchPopularDecksQuery{
+		Cards:          r.Cards,
+		Search:         regexp.QuoteMeta(r.Search),
+		Regions:        r.Regions,
+		Types:          r.Types,
+		FeaturedPlayer: r.FeaturedPlayer,
+		Sorting:        r.Sorting,
+		SortAsc:        r.SortAsc,
+		Page:           r.Page,
+		Limit:          limit,
+	}
+}
+
+type PopularDecksResponse struct {
+	Decks    []models.Deck `json:"decks"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	Limit    int           `json:"limit"`
+	HasMore  bool          `json:"hasMore"`
+	NextPage *int          `json:"nextPage"`
+}
+
+func GetPopularDecks(c echo.Context) error {
+	r := new(PopularDecksRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	query := r.toQuery()
+
+	decks, err := models.Decks.GetPopularDecks(query)
+	if err != nil {
+		return err
+	}
+	if decks == nil {
+		decks = make([]models.Deck, 0)
+	}
+
+	total, err := models.Decks.CountPopularDecks(query)
+	if err != nil {
+		return err
+	}
+
+	response := PopularDecksResponse{
+		Decks: decks,
+		Total: total,
+		Page:  query.Page,
+		Limit: query.Limit,
+	}
+
+	if (query.Page+1)*query.Limit < total {
+		nextPage := query.Page + 1
+		response.HasMore = true
+		response.NextPage = &nextPage
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
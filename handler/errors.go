@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HTTPErrorHandler sends every error as a types.APIError, so clients always get a {code, message, details} body
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	apiError := toAPIError(err)
+	if apiError.Status >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(apiError.Status)
+	} else {
+		err = c.JSON(apiError.Status, apiError)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+func toAPIError(err error) *types.APIError {
+	switch e := err.(type) {
+	case *types.APIError:
+		return e
+	case *types.InvalidDeckError:
+		apiError := types.NewAPIError(http.StatusBadRequest, types.CodeInvalidDeck, e.Err.Error())
+		apiError.Details = e.Details
+		return apiError
+	case *echo.HTTPError:
+		message := http.StatusText(e.Code)
+		if e.Message != nil {
+			message = fmt.Sprint(e.Message)
+		}
+		return types.NewAPIError(e.Code, types.CodeForStatus(e.Code), message)
+	}
+
+	if err == mongo.ErrNoDocuments {
+		return types.ErrNotFound
+	}
+
+	return types.NewAPIError(http.StatusInternalServerError, types.CodeInternal, "Internal server error")
+}
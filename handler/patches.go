@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+)
+
+type PatchChangesResponse struct {
+	Version string                 `json:"version"`
+	Cards   []*models.CardRevision `json:"cards"`
+}
+
+func GetPatchChanges(c echo.Context) error {
+	version := c.Param("version")
+
+	revisions, err := models.CardRevisions.GetPatchChanges(version)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return types.ErrNotFound
+	}
+
+	return c.JSON(http.StatusOK, PatchChangesResponse{
+		Version: version,
+		Cards:   revisions,
+	})
+}
+
+func GetPatchAffectedDecks(c echo.Context) error {
+	affected, err := models.AffectedDecks.GetAffectedDecks(c.Param("version"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, affected)
+}
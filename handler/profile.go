@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SocialLinksRequest struct {
+	Instagram string `json:"instagram" validate:"omitempty,max=30,handle"`
+	Facebook  string `json:"facebook" validate:"omitempty,max=200,url"`
+	Twitter   string `json:"twitter" validate:"omitempty,max=15,handle"`
+	Discord   string `json:"discord" validate:"omitempty,max=40"`
+	Twitch    string `json:"twitch" validate:"omitempty,max=25,handle"`
+}
+
+func (r *SocialLinksRequest) normalize() {
+	r.Instagram = strings.TrimPrefix(strings.TrimSpace(r.Instagram), "@")
+	r.Facebook = strings.TrimSpace(r.Facebook)
+	r.Twitter = strings.TrimPrefix(strings.TrimSpace(r.Twitter), "@")
+	r.Discord = strings.TrimSpace(r.Discord)
+	r.Twitch = strings.TrimPrefix(strings.TrimSpace(r.Twitch), "@")
+}
+
+func (r SocialLinksRequest) toSocialLinks() models.SocialLinks {
+	return models.SocialLinks{
+		Instagram: r.Instagram,
+		Facebook:  r.Facebook,
+		Twitter:   r.Twitter,
+		Discord:   r.Discord,
+		Twitch:    r.Twitch,
+	}
+}
+
+// UpdateProfileRequest only changes the fields that are sent
+type UpdateProfileRequest struct {
+	Username        string              `json:"username" validate:"omitempty,min=3,max=30,handle"`
+	Socials         *SocialLinksRequest `json:"socials"`
+	CurrentPassword string              `json:"currentPassword"`
+	NewPassword     string              `json:"newPassword" validate:"omitempty,min=8"`
+}
+
+type ProfileResponse struct {
+	User  models.PublicUser `json:"user"`
+	Decks []*models.Deck    `json:"decks"`
+}
+
+func UpdateProfile(c echo.Context) error {
+	claims, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	r := new(UpdateProfileRequest)
+	if err := c.Bind(r); err != nil {
+		return types.BadRequest(err.Error())
+	}
+	if r.Socials != nil {
+		r.Socials.normalize()
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+
+	user, err := models.Users.GetUserById(claims.UserID())
+	if err != nil {
+		return err
+	}
+
+	// Everything that can reject the request is checked before anything is written
+	changeUsername := len(r.Username) > 0 && r.Username != user.Username
+	if changeUsername {
+		if err := models.Users.CheckUsernameAvailable(user.UserID(), r.Username); err != nil {
+			return err
+		}
+	}
+	if len(r.NewPassword) > 0 && !models.CheckPasswordHash(r.CurrentPassword, user.Password) {
+		return types.BadRequest("Your current password is incorrect")
+	}
+
+	if changeUsername {
+		if user, err = models.Users.SetUsername(user.UserID(), r.Username); err != nil {
+			return err
+		}
+
+		if _, err := models.Decks.SetOwnerUsername(user.UserID(), user.Username); err != nil {
+			return err
+		}
+	}
+
+	if r.Socials != nil {
+		if user, err = models.Users.SetSocials(user.UserID(), r.Socials.toSocialLinks()); err != nil {
+			return err
+		}
+	}
+
+	if len(r.NewPassword) > 0 {
+		if user, err = models.Users.SetPassword(user.UserID(), r.NewPassword); err != nil {
+			return err
+		}
+
+		sessionID, err := utils.SessionFromContext(c)
+		if err != nil {
+			return err
+		}
+		if _, err := models.Sessions.RevokeOtherSessions(user.UserID(), sessionID); err != nil {
+			return err
+		}
+	}
+
+	// The access token carries the public user, so it has to be reissued for the changes to show up
+	publicUser := user.Public()
+	if sessionID, err := utils.SessionFromContext(c); err == nil {
+		if jwtCookie := utils.CreateJWTCookie(publicUser, sessionID); jwtCookie != nil {
+			c.SetCookie(jwtCookie)
+		}
+	}
+
+	return c.JSON(http.StatusOK, publicUser)
+}
+
+// GetProfile shares /users with other routes, so their paths are reserved usernames (see models.IsUsernameReserved)
+func GetProfile(c echo.Context) error {
+	user, err := models.Users.GetUserByUsername(regexp.QuoteMeta(c.Param("username")))
+	if err == mongo.ErrNoDocuments {
+		return types.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	decks, err := models.Decks.GetDecksByOwnerID(user.UserID())
+	if err != nil {
+		return err
+	}
+
+	publishedDecks := make([]*models.Deck, 0)
+	for _, deck := range decks {
+		if deck.Published && !deck.Deleted {
+			publishedDecks = append(publishedDecks, deck)
+		}
+	}
+
+	return c.JSON(http.StatusOK, ProfileResponse{
+		User:  user.Public(),
+		Decks: publishedDecks,
+	})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionResponse struct {
+	*models.Session
+	Current bool `json:"current"`
+}
+
+func GetSessions(c echo.Context) error {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+	currentSession, err := utils.SessionFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := models.Sessions.GetActiveSessions(user.UserID())
+	if err != nil {
+		return err
+	}
+
+	response := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = SessionResponse{
+			Session: session,
+			Current: session.SessionID() == currentSession,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func RevokeSession(c echo.Context) error {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = models.Sessions.RevokeSession(user.UserID(), c.Param("id"))
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return types.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if currentSession, _ := utils.SessionFromContext(c); currentSession == c.Param("id") {
+		utils.ClearAuthCookies(c)
+	}
+
+	return c.JSON(http.StatusOK, true)
+}
+
+func RevokeAllSessions(c echo.Context) error {
+	user, err := utils.UserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := models.Sessions.RevokeAllSessions(user.UserID())
+	if err != nil {
+		return err
+	}
+
+	utils.ClearAuthCookies(c)
+
+	return c.JSON(http.StatusOK, map[string]int64{"revoked": revoked})
+}
@@ -1,11 +1,9 @@
 package handler
 
 import (
-	"net/http"
-	"time"
-
 	"github.com/labstack/echo"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
 )
 
@@ -17,7 +15,7 @@ type LoginRequest struct {
 func Login(c echo.Context) error {
 	u := new(LoginRequest)
 	if err := c.Bind(u); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return types.BadRequest(err.Error())
 	}
 	if err := c.Validate(u); err != nil {
 		return err
@@ -31,25 +29,64 @@ func Login(c echo.Context) error {
 		return err
 	}
 
-	jwtCookie := utils.CreateJWTCookie(*user)
+	session, refreshToken, err := models.Sessions.CreateSession(user.UserID(), c.Request().UserAgent(), c.RealIP())
+	if err != nil {
+		return err
+	}
+
+	publicUser := user.Public()
+	setAuthCookies(c, publicUser, session.SessionID(), refreshToken)
+
+	return c.JSON(200, publicUser)
+}
+
+func setAuthCookies(c echo.Context, user models.PublicUser, sessionID, refreshToken string) {
+	jwtCookie := utils.CreateJWTCookie(user, sessionID)
 	if jwtCookie != nil {
 		c.SetCookie(jwtCookie)
 	}
-
-	return c.JSON(200, user)
+	c.SetCookie(utils.CreateRefreshCookie(refreshToken))
 }
 
 func Logout(c echo.Context) error {
-	emptyCookie := &http.Cookie{
-		Name:    "authtoken",
-		Value:   "",
-		Expires: time.Unix(0, 0),
+	if user, err := utils.UserFromContext(c); err == nil {
+		sessionID, _ := utils.SessionFromContext(c)
+		models.Sessions.RevokeSession(user.UserID(), sessionID)
+	} else if refreshCookie := utils.GetRefreshCookie(c.Cookies()); refreshCookie != nil {
+		models.Sessions.RevokeRefreshToken(refreshCookie.Value)
 	}
-	c.SetCookie(emptyCookie)
+
+	utils.ClearAuthCookies(c)
 
 	return c.JSON(200, true)
 }
 
+func Refresh(c echo.Context) error {
+	refreshCookie := utils.GetRefreshCookie(c.Cookies())
+	if refreshCookie == nil {
+		return types.Unauthorized("Invalid refresh token")
+	}
+
+	session, refreshToken, err := models.Sessions.RotateSession(refreshCookie.Value, c.Request().UserAgent(), c.RealIP())
+	if err == models.ErrInvalidRefreshToken {
+		utils.ClearAuthCookies(c)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := models.Users.GetUserById(session.UserID)
+	if err != nil {
+		return err
+	}
+
+	publicUser := user.Public()
+	setAuthCookies(c, publicUser, session.SessionID(), refreshToken)
+
+	return c.JSON(200, publicUser)
+}
+
 type RegisterRequest struct {
 	Username string `json:"username" bson:"username" validate:"required"`
 	Email    string `json:"email" bson:"email" validate:"required,email"`
@@ -59,7 +96,7 @@ type RegisterRequest struct {
 func Register(c echo.Context) error {
 	u := new(RegisterRequest)
 	if err := c.Bind(u); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return types.BadRequest(err.Error())
 	}
 	if err := c.Validate(u); err != nil {
 		return err
@@ -74,7 +111,9 @@ func Register(c echo.Context) error {
 		return err
 	}
 
-	return c.JSON(200, user)
+	logMailError(sendVerificationEmail(user))
+
+	return c.JSON(200, user.Public())
 }
 
 func SearchUsers(c echo.Context) error {
@@ -86,13 +125,18 @@ func SearchUsers(c echo.Context) error {
 		return err
 	}
 
-	return c.JSON(200, users)
+	publicUsers := make([]models.PublicUser, len(users))
+	for i, user := range users {
+		publicUsers[i] = user.Public()
+	}
+
+	return c.JSON(200, publicUsers)
 }
 
 func ValidateEmail(c echo.Context) error {
 	email := c.QueryParam("email")
 	if len(email) == 0 {
-		return echo.ErrBadRequest
+		return types.BadRequest("email is required")
 	}
 
 	user, _ := models.Users.GetUserByEmail(email)
@@ -103,7 +147,11 @@ func ValidateEmail(c echo.Context) error {
 func ValidateUsername(c echo.Context) error {
 	username := c.QueryParam("username")
 	if len(username) == 0 {
-		return echo.ErrBadRequest
+		return types.BadRequest("username is required")
+	}
+
+	if models.IsUsernameReserved(username) {
+		return c.JSON(200, false)
 	}
 
 	user, _ := models.Users.GetUserByUsername(username)
@@ -112,15 +160,9 @@ func ValidateUsername(c echo.Context) error {
 }
 
 func Auth(c echo.Context) error {
-	cookie := utils.GetJWTCookie(c.Cookies())
-
-	if cookie == nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid auth token")
-	}
-
-	user, err := utils.DecodeToken(cookie.Value)
+	user, err := utils.UserFromContext(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Could not decode auth token")
+		return err
 	}
 
 	return c.JSON(200, user)
@@ -1,20 +1,66 @@
 package handler
 
 import (
-	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator"
-	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
 )
 
+// handlePattern matches usernames and social media handles
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
 type Validator struct {
 	Validator *validator.Validate
 }
 
+// NewValidator creates a validator with the custom tags used by the request types
+func NewValidator() *Validator {
+	v := validator.New()
+	v.RegisterValidation("handle", func(fl validator.FieldLevel) bool {
+		return handlePattern.MatchString(fl.Field().String())
+	})
+	v.RegisterTagNameFunc(fieldName)
+
+	return &Validator{Validator: v}
+}
+
 func (cv *Validator) Validate(i interface{}) error {
-	if err := cv.Validator.Struct(i); err != nil {
-		// Optionally, you could return the error to give each route more control over the status code
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	err := cv.Validator.Struct(i)
+	if err == nil {
+		return nil
 	}
-	return nil
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return types.BadRequest(err.Error())
+	}
+
+	fields := make([]types.FieldError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		fields[i] = types.FieldError{
+			Field: fieldError.Field(),
+			Rule:  fieldError.Tag(),
+			Param: fieldError.Param(),
+		}
+	}
+
+	return types.Validation("Validation failed", fields)
+}
+
+// fieldName reports fields by the name the client sent them as, rather than the Go field name
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if len(name) > 0 {
+			return name
+		}
+	}
+
+	return field.Name
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/utils"
+)
+
+// importCommand loads cards and globals from local Data Dragon bundles, e.g.
+//
+//	doruneterraapi-go import set1-en_us.zip set2-en_us.zip core-en_us.zip
+func importCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	testing := flags.Bool("test", false, "import into the test database")
+	flags.Usage = func() {
+		log.Println("Usage: import [-test] <bundle zip or directory>...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("No bundles given")
+	}
+
+	databaseConfig := config.Config.Database
+	if *testing {
+		databaseConfig = config.TestConfig.Database
+	}
+
+	database := db.New(databaseConfig)
+	if err := database.Connect(); err != nil {
+		return err
+	}
+	database.WaitForConnection()
+	models.InitModels(database)
+
+	updated, err := utils.NewCardSync().Import(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Imported %v new or changed cards", updated)
+	return nil
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// LogMailer writes emails to a file, or to the log when no file is configured, instead of sending them.
+// It is meant for local development and tests.
+type LogMailer struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{
+		path: path,
+	}
+}
+
+func (m *LogMailer) Send(message Message) error {
+	formatted := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n\n", message.To, message.Subject, message.Body)
+
+	if len(m.path) == 0 {
+		log.Print(formatted)
+		return nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(formatted)
+	return err
+}
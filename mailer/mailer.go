@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(message Message) error
+}
+
+var Default Mailer
+
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if len(cfg.Host) == 0 {
+			return nil, errors.New("No SMTP host is configured")
+		}
+		return NewSMTPMailer(cfg), nil
+	case "log":
+		return NewLogMailer(cfg.File), nil
+	case "":
+		return nil, errors.New("No mail driver is configured")
+	default:
+		return nil, fmt.Errorf("Unknown mail driver %s", cfg.Driver)
+	}
+}
+
+func Init(cfg config.MailConfig) error {
+	m, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	Default = m
+	return nil
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+)
+
+func TestNew(t *testing.T) {
+	m, err := New(config.MailConfig{Driver: "log"})
+	assert.Nil(t, err)
+	assert.IsType(t, &LogMailer{}, m)
+
+	m, err = New(config.MailConfig{Driver: "smtp", Host: "localhost", Port: 25})
+	assert.Nil(t, err)
+	assert.IsType(t, &SMTPMailer{}, m)
+
+	_, err = New(config.MailConfig{Driver: "carrier pigeon"})
+	assert.NotNil(t, err)
+
+	_, err = New(config.MailConfig{Driver: "smtp"})
+	assert.NotNil(t, err)
+
+	_, err = New(config.MailConfig{})
+	assert.NotNil(t, err)
+}
+
+func TestLogMailerFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mailer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "mail.log")
+	m := NewLogMailer(path)
+
+	assert.Nil(t, m.Send(PasswordResetMessage("test@test.com", "test_user", "http://localhost/reset")))
+	assert.Nil(t, m.Send(EmailVerificationMessage("test@test.com", "test_user", "http://localhost/verify")))
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "To: test@test.com")
+	assert.Contains(t, string(data), "http://localhost/reset")
+	assert.Contains(t, string(data), "http://localhost/verify")
+}
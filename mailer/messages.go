@@ -0,0 +1,29 @@
+package mailer
+
+import "fmt"
+
+func PasswordResetMessage(to, username, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your Decks of Runeterra password",
+		Body: fmt.Sprintf(`Hi %s,
+
+Someone asked to reset the password for your Decks of Runeterra account. If it was you, use the link below to choose a new password. The link expires in one hour and can only be used once.
+
+%s
+
+If you didn't ask for this, you can ignore this email.`, username, link),
+	}
+}
+
+func EmailVerificationMessage(to, username, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your Decks of Runeterra email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Please confirm this is your email address by opening the link below. The link expires in 24 hours.
+
+%s`, username, link),
+	}
+}
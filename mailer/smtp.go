@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+)
+
+type SMTPMailer struct {
+	address string
+	auth    smtp.Auth
+	from    string
+}
+
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	var auth smtp.Auth
+	if len(cfg.Username) > 0 {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &SMTPMailer{
+		address: cfg.Host + ":" + strconv.Itoa(cfg.Port),
+		auth:    auth,
+		from:    cfg.From,
+	}
+}
+
+func (m *SMTPMailer) Send(message Message) error {
+	headers := []string{
+		fmt.Sprintf("From: %s", m.from),
+		fmt.Sprintf("To: %s", message.To),
+		fmt.Sprintf("Subject: %s", message.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body
+
+	return smtp.SendMail(m.address, m.auth, m.from, []string{message.To}, []byte(body))
+}
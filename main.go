@@ -1,6 +1,9 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	"github.com/labstack/echo"
 	"github.com/robfig/cron"
 
@@ -11,19 +14,36 @@ import (
 )
 
 func main() {
-	database := db.New(config.Config.Database)
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := importCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	c := cron.New()
-	go utils.UpdateAllSets(database)
-	c.AddFunc("0 */48 * * *", func() { go utils.UpdateAllSets(database) })
-	c.Start()
+	database := db.New(config.Config.Database)
 
 	e := echo.New()
 
 	app := app.New(e, database)
 
+	if err := app.Init(); err != nil {
+		panic(err)
+	}
+
+	c := cron.New()
+	go syncCards()
+	c.AddFunc("0 */48 * * *", func() { go syncCards() })
+	c.Start()
+
 	err := app.Run(":1323")
 	if err != nil {
 		panic(err)
 	}
 }
+
+func syncCards() {
+	if err := utils.DefaultCardSync.UpdateAllSets(); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AffectedDeck records that a published deck used cards changed in a patch, so its owner can review it
+type AffectedDeck struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Version     string             `json:"version" bson:"version"`
+	DeckID      string             `json:"deckId" bson:"deckId"`
+	Title       string             `json:"title" bson:"title"`
+	Owner       string             `json:"owner" bson:"owner"`
+	Cards       []string           `json:"cards" bson:"cards"`
+	DateCreated time.Time          `json:"dateCreated" bson:"dateCreated"`
+}
+
+// NewAffectedDeck records the deck for the version, listing which of the changed cards it uses
+func NewAffectedDeck(deck *Deck, version string, changedCards []string) AffectedDeck {
+	changed := make(map[string]bool, len(changedCards))
+	for _, cardID := range changedCards {
+		changed[cardID] = true
+	}
+
+	cards := make([]string, 0)
+	for _, card := range deck.Cards {
+		if changed[card.CardID] {
+			cards = append(cards, card.CardID)
+		}
+	}
+
+	return AffectedDeck{
+		Version:     version,
+		DeckID:      deck.ID,
+		Title:       deck.Title,
+		Owner:       deck.Owner,
+		Cards:       cards,
+		DateCreated: time.Now(),
+	}
+}
+
+type AffectedDeckModel struct {
+	collection *mongo.Collection
+}
+
+func InitAffectedDeckModel(d *db.Database) *AffectedDeckModel {
+	collection := d.Collection("affected_decks")
+	indices := make([]mongo.IndexModel, 2)
+	indices[0] = mongo.IndexModel{
+		Keys: bson.D{{Key: "version", Value: 1}},
+	}
+	indices[1] = mongo.IndexModel{
+		Keys: bson.D{{Key: "owner", Value: 1}, {Key: "dateCreated", Value: -1}},
+	}
+
+	_, err := collection.Indexes().CreateMany(
+		context.Background(),
+		indices,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewAffectedDeckModel(collection)
+}
+
+func NewAffectedDeckModel(c *mongo.Collection) *AffectedDeckModel {
+	return &AffectedDeckModel{
+		collection: c,
+	}
+}
+
+func (m *AffectedDeckModel) SaveAffectedDecks(affected []AffectedDeck) error {
+	if len(affected) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Each deck is recorded once per version, so retrying a failed sync doesn't repeat it
+	operations := make([]mongo.WriteModel, len(affected))
+	for i, deck := range affected {
+		operations[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"deckId": deck.DeckID, "version": deck.Version}).
+			SetReplacement(deck).
+			SetUpsert(true)
+	}
+
+	_, err := m.collection.BulkWrite(ctx, operations)
+	return err
+}
+
+func (m *AffectedDeckModel) findAffectedDecks(filter bson.M, sort bson.D) ([]*AffectedDeck, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var affected []*AffectedDeck
+	cur, err := m.collection.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close(ctx)
+
+	if err := cur.All(ctx, &affected); err != nil {
+		return nil, err
+	}
+
+	if affected == nil {
+		affected = make([]*AffectedDeck, 0)
+	}
+
+	return affected, nil
+}
+
+// GetAffectedDecks returns the decks affected by a Data Dragon version
+func (m *AffectedDeckModel) GetAffectedDecks(version string) ([]*AffectedDeck, error) {
+	return m.findAffectedDecks(bson.M{"version": version}, bson.D{{Key: "deckId", Value: 1}})
+}
+
+// GetOwnerAffectedDecks returns the user's decks that patches have affected, newest first
+func (m *AffectedDeckModel) GetOwnerAffectedDecks(owner string) ([]*AffectedDeck, error) {
+	return m.findAffectedDecks(bson.M{"owner": owner}, bson.D{{Key: "dateCreated", Value: -1}})
+}
@@ -0,0 +1,94 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+func TestPatchAffectedBadge(t *testing.T) {
+	badge := models.PatchAffectedBadge("1.10.0")
+	assert.Equal(t, "Affected by patch 1.10.0", badge.Text)
+	assert.True(t, badge.IsPatchAffected())
+
+	assert.True(t, models.PatchAffectedBadge("").IsPatchAffected())
+	assert.False(t, models.DeckBadge{Color: "gold", Text: "Featured"}.IsPatchAffected())
+}
+
+func TestFlagPatchAffectedDecks(t *testing.T) {
+	cards := []models.CardQuantity{{CardID: "patched_card", Quantity: 3}, {CardID: "01IO012", Quantity: 2}}
+	published, err := models.Decks.SaveDeck(models.Deck{Title: "Patched", Owner: "patch_owner", Cards: cards})
+	if err != nil {
+		panic(err)
+	}
+	if _, err := models.Decks.PublishDeck(published.ID); err != nil {
+		panic(err)
+	}
+	unpublished, err := models.Decks.SaveDeck(models.Deck{Title: "Private", Owner: "patch_owner", Cards: cards})
+	if err != nil {
+		panic(err)
+	}
+
+	badge := models.PatchAffectedBadge("9.9.9")
+	decks, err := models.Decks.FlagPatchAffectedDecks([]string{"patched_card"}, badge)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(decks))
+	assert.Equal(t, published.ID, decks[0].ID)
+
+	received, err := models.Decks.GetDeck(published.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, badge, received.Badge)
+
+	received, err = models.Decks.GetDeck(unpublished.ID)
+	assert.Nil(t, err)
+	assert.False(t, received.Badge.IsPatchAffected())
+
+	affected := models.NewAffectedDeck(decks[0], "9.9.9", []string{"patched_card", "other_card"})
+	assert.Equal(t, []string{"patched_card"}, affected.Cards)
+
+	err = models.AffectedDecks.SaveAffectedDecks([]models.AffectedDeck{affected})
+	assert.Nil(t, err)
+
+	err = models.AffectedDecks.SaveAffectedDecks([]models.AffectedDeck{affected})
+	assert.Nil(t, err)
+
+	byVersion, err := models.AffectedDecks.GetAffectedDecks("9.9.9")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(byVersion))
+
+	byOwner, err := models.AffectedDecks.GetOwnerAffectedDecks("patch_owner")
+	assert.Nil(t, err)
+	assert.Equal(t, published.ID, byOwner[0].DeckID)
+}
+
+func TestFlagPatchAffectedDecksKeepsBadges(t *testing.T) {
+	cards := []models.CardQuantity{{CardID: "badged_card", Quantity: 3}}
+	featuredBadge := models.DeckBadge{Color: "gold", Text: "Featured"}
+	featured, err := models.Decks.SaveDeck(models.Deck{Title: "Featured", Owner: "badge_owner", Cards: cards, Badge: featuredBadge})
+	if err != nil {
+		panic(err)
+	}
+	patched, err := models.Decks.SaveDeck(models.Deck{Title: "Patched", Owner: "badge_owner", Cards: cards, Badge: models.PatchAffectedBadge("9.9.8")})
+	if err != nil {
+		panic(err)
+	}
+	for _, deck := range []*models.Deck{featured, patched} {
+		if _, err := models.Decks.PublishDeck(deck.ID); err != nil {
+			panic(err)
+		}
+	}
+
+	badge := models.PatchAffectedBadge("9.9.9")
+	decks, err := models.Decks.FlagPatchAffectedDecks([]string{"badged_card"}, badge)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(decks))
+
+	received, err := models.Decks.GetDeck(featured.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, featuredBadge, received.Badge)
+
+	received, err = models.Decks.GetDeck(patched.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, badge, received.Badge)
+}
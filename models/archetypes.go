@@ -12,6 +12,7 @@ import (
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type CardInArchetype struct {
@@ -87,16 +88,26 @@ func (a *Archetype) CalculateDetails() error {
 	return nil
 }
 
+// recordQuantity counts the copies towards how often the card appears as a 1-of, 2-of or 3-of.
+// Cards with more copies count as 3-ofs.
+func recordQuantity(appears []int, quantity int) {
+	index := quantity - 1
+	if index >= len(appears) {
+		index = len(appears) - 1
+	}
+	appears[index] += quantity
+}
+
 func addCardQuantity(quantities []CardInArchetype, quantity CardQuantity) []CardInArchetype {
+	if quantity.Quantity < 1 {
+		return quantities
+	}
+
 	for i := 0; i < len(quantities); i++ {
 		quant := quantities[i]
 		if quant.CardID == quantity.CardID {
 			quant.Quantity += quantity.Quantity
-			for j := 0; j < len(quant.QuantityAppears); j++ {
-				if j+1 == quantity.Quantity {
-					quant.QuantityAppears[j] += quantity.Quantity
-				}
-			}
+			recordQuantity(quant.QuantityAppears, quantity.Quantity)
 
 			quantities[i] = quant
 
@@ -110,7 +121,7 @@ func addCardQuantity(quantities []CardInArchetype, quantity CardQuantity) []Card
 		QuantityAppears: []int{0, 0, 0},
 	}
 
-	newQuantity.QuantityAppears[quantity.Quantity-1] += quantity.Quantity
+	recordQuantity(newQuantity.QuantityAppears, quantity.Quantity)
 
 	quantities = append(quantities, newQuantity)
 
@@ -176,6 +187,9 @@ func (a *PopulatedArchetype) CalculateKeywords() []KeywordsInArchetype {
 
 		for _, quant := range cards {
 			card := Cards.GetCard(quant.CardID)
+			if card == nil {
+				continue
+			}
 			for _, keyword := range card.Keywords {
 				keywords = addKeyword(keywords, keyword, quant.Quantity)
 				total += quant.Quantity
@@ -270,8 +284,68 @@ func (m *ArchetypesModel) SaveArchetype(archetype Archetype) (*Archetype, error)
 	return &newArchetype, nil
 }
 
+func (m *ArchetypesModel) UpdateArchetype(archetype Archetype) (*Archetype, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	archetypeID := archetype.ID
+
+	archetype.ID = ""
+
+	var updatedArchetype Archetype
+	after := options.After
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	curr := m.collection.FindOneAndUpdate(ctx, bson.M{"_id": archetypeID}, bson.M{"$set": archetype}, &options)
+	err := curr.Decode(&updatedArchetype)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedArchetype, nil
+}
+
+func (m *ArchetypesModel) DeleteArchetype(archetypeID string) (*Archetype, error) {
+	return m.setArchetypeFields(archetypeID, bson.M{"deleted": true})
+}
+
+func (m *ArchetypesModel) SetArchetypeHidden(archetypeID string, hidden bool) (*Archetype, error) {
+	return m.setArchetypeFields(archetypeID, bson.M{"hidden": hidden})
+}
+
+func (m *ArchetypesModel) setArchetypeFields(archetypeID string, fields bson.M) (*Archetype, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var updatedArchetype Archetype
+	after := options.After
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	curr := m.collection.FindOneAndUpdate(ctx, bson.M{"_id": archetypeID}, bson.M{"$set": fields}, &options)
+	err := curr.Decode(&updatedArchetype)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedArchetype, nil
+}
+
+func (m *ArchetypesModel) GetArchetypeBySanitizedTitle(sanitizedTitle string) (*Archetype, error) {
+	var archetype Archetype
+
+	result := m.collection.FindOne(context.Background(), bson.M{"deleted": false, "sanitizedTitle": sanitizedTitle})
+	err := result.Decode(&archetype)
+	if err != nil {
+		return nil, err
+	}
+	return &archetype, nil
+}
+
 func (m *ArchetypesModel) GetArchetypes() ([]PopulatedArchetype, error) {
-	return m.queryPopulatedArchetypes(bson.M{"deleted": false})
+	return m.queryPopulatedArchetypes(bson.M{"deleted": false, "hidden": false})
 }
 
 func (m *ArchetypesModel) GetArchetypesRaw() ([]*Archetype, error) {
@@ -292,9 +366,9 @@ func (m *ArchetypesModel) GetArchetypesRaw() ([]*Archetype, error) {
 }
 
 func (m *ArchetypesModel) GetDeckArchetypes(deckID string) ([]PopulatedArchetype, error) {
-	return m.queryPopulatedArchetypes(bson.M{"deleted": false, "decks": deckID})
+	return m.queryPopulatedArchetypes(bson.M{"deleted": false, "hidden": false, "decks": deckID})
 }
 
 func (m *ArchetypesModel) GetCardArchetypes(cardID string) ([]PopulatedArchetype, error) {
-	return m.queryPopulatedArchetypes(bson.M{"deleted": false, "keyCards.card": cardID})
+	return m.queryPopulatedArchetypes(bson.M{"deleted": false, "hidden": false, "keyCards.card": cardID})
 }
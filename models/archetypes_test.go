@@ -68,6 +68,24 @@ func TestCalculateKeyCards(t *testing.T) {
 	assert.Equal(t, expected, keyCards)
 }
 
+func TestCalculateKeyCardsQuantities(t *testing.T) {
+	popArch := models.PopulatedArchetype{
+		Decks: []models.Deck{
+			{Cards: []models.CardQuantity{{CardID: "01FR024", Quantity: 0}, {CardID: "01IO012", Quantity: 5}}},
+			{Cards: []models.CardQuantity{{CardID: "01FR024", Quantity: 4}, {CardID: "01IO012", Quantity: -1}}},
+			{Cards: []models.CardQuantity{{CardID: "01IO012", Quantity: 1}}},
+		},
+	}
+
+	keyCards := popArch.CalculateKeyCards()
+	expected := []models.CardInArchetype{
+		{CardID: "01IO012", Quantity: 6, QuantityAppears: []int{1, 0, 5}},
+		{CardID: "01FR024", Quantity: 4, QuantityAppears: []int{0, 0, 4}},
+	}
+
+	assert.Equal(t, expected, keyCards)
+}
+
 func TestCalculateArchetypeRegions(t *testing.T) {
 	archetype := models.Archetype{
 		Decks: []string{SavedDecks[0].ID, SavedDecks[1].ID},
@@ -119,6 +137,22 @@ func TestCalculateKeywords(t *testing.T) {
 	}
 }
 
+func TestCalculateKeywordsUnknownCard(t *testing.T) {
+	savedCards := models.Cards
+	defer func() { models.Cards = savedCards }()
+
+	models.Cards = models.NewCardModel(nil)
+	models.Cards.SetCards([]models.Card{{ID: "01DE001", CardCode: "01DE001", Keywords: []string{"Tough"}}})
+
+	popArch := models.PopulatedArchetype{Decks: []models.Deck{{Cards: []models.CardQuantity{
+		{CardID: "01DE001", Quantity: 2},
+		{CardID: "missing", Quantity: 1},
+	}}}}
+
+	keywords := popArch.CalculateKeywords()
+	assert.Equal(t, []models.KeywordsInArchetype{{Keyword: "Tough", Quantity: 2, Pct: 1}}, keywords)
+}
+
 func TestCalculateDetails(t *testing.T) {
 	archetype := models.Archetype{
 		Decks: []string{SavedDecks[0].ID, SavedDecks[1].ID},
@@ -144,3 +178,61 @@ func TestSaveArchetype(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, saved.ID)
 }
+
+func TestUpdateArchetype(t *testing.T) {
+	saved, err := models.Archetypes.SaveArchetype(models.Archetype{Title: "Update Me", SanitizedTitle: "update-me"})
+	if err != nil {
+		panic(err)
+	}
+
+	saved.Title = "Updated Archetype"
+	saved.SanitizedTitle = saved.SanitizeTitle()
+	received, err := models.Archetypes.UpdateArchetype(*saved)
+
+	assert.Nil(t, err)
+	assert.Equal(t, saved.ID, received.ID)
+	assert.Equal(t, "Updated Archetype", received.Title)
+
+	found, err := models.Archetypes.GetArchetypeBySanitizedTitle("updated-archetype")
+
+	assert.Nil(t, err)
+	assert.Equal(t, saved.ID, found.ID)
+}
+
+func TestDeleteArchetype(t *testing.T) {
+	saved, err := models.Archetypes.SaveArchetype(models.Archetype{Title: "Delete Me", SanitizedTitle: "delete-me"})
+	if err != nil {
+		panic(err)
+	}
+
+	deleted, err := models.Archetypes.DeleteArchetype(saved.ID)
+
+	assert.Nil(t, err)
+	assert.True(t, deleted.Deleted)
+
+	_, err = models.Archetypes.GetArchetypeBySanitizedTitle("delete-me")
+	assert.NotNil(t, err)
+}
+
+func TestSetArchetypeHidden(t *testing.T) {
+	saved, err := models.Archetypes.SaveArchetype(models.Archetype{Decks: []string{SavedDecks[0].ID}})
+	if err != nil {
+		panic(err)
+	}
+
+	hidden, err := models.Archetypes.SetArchetypeHidden(saved.ID, true)
+
+	assert.Nil(t, err)
+	assert.True(t, hidden.Hidden)
+
+	recv, err := models.Archetypes.GetArchetypes()
+	assert.Nil(t, err)
+	for _, archetype := range recv {
+		assert.NotEqual(t, saved.ID, archetype.ID)
+	}
+
+	visible, err := models.Archetypes.SetArchetypeHidden(saved.ID, false)
+
+	assert.Nil(t, err)
+	assert.False(t, visible.Hidden)
+}
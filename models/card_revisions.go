@@ -0,0 +1,186 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FieldChange is one card field that was changed by a patch, named as in the card's JSON
+type FieldChange struct {
+	Field string      `json:"field" bson:"field"`
+	Old   interface{} `json:"old" bson:"old"`
+	New   interface{} `json:"new" bson:"new"`
+}
+
+// CardRevision records a card as it was after a sync, along with what changed since the previous revision
+type CardRevision struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	CardID      string             `json:"card_id" bson:"card_id"`
+	Version     string             `json:"version" bson:"version"`
+	New         bool               `json:"new" bson:"new"`
+	Changes     []FieldChange      `json:"changes" bson:"changes"`
+	Card        Card               `json:"card" bson:"card"`
+	DateCreated time.Time          `json:"date_created" bson:"date_created"`
+}
+
+// DiffCards lists the fields that differ between two versions of a card. Translations are left out, since the
+// default locale text already shows what changed.
+func DiffCards(old, new Card) []FieldChange {
+	changes := make([]FieldChange, 0)
+	oldValue := reflect.ValueOf(old)
+	newValue := reflect.ValueOf(new)
+	cardType := oldValue.Type()
+
+	for i := 0; i < cardType.NumField(); i++ {
+		field := cardType.Field(i)
+		if field.Name == "Localized" {
+			continue
+		}
+
+		oldField := oldValue.Field(i).Interface()
+		newField := newValue.Field(i).Interface()
+		if !cmp.Equal(oldField, newField) {
+			changes = append(changes, FieldChange{
+				Field: strings.Split(field.Tag.Get("json"), ",")[0],
+				Old:   oldField,
+				New:   newField,
+			})
+		}
+	}
+
+	return changes
+}
+
+// cosmeticFields are card fields whose changes don't affect how the card plays. Rules text isn't one of them,
+// since balance changes are often only visible in the description.
+var cosmeticFields = map[string]bool{
+	"flavorText": true,
+	"artistName": true,
+}
+
+// AffectsGameplay reports whether an existing card changed in a way that matters to decks using it
+func (r CardRevision) AffectsGameplay() bool {
+	if r.New {
+		return false
+	}
+
+	for _, change := range r.Changes {
+		if !cosmeticFields[change.Field] {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCardRevision describes how a card changed in the version. previous is nil for cards that are new.
+func NewCardRevision(previous *Card, card Card, version string) CardRevision {
+	revision := CardRevision{
+		CardID:      card.ID,
+		Version:     version,
+		New:         previous == nil,
+		Changes:     make([]FieldChange, 0),
+		Card:        card,
+		DateCreated: time.Now(),
+	}
+	revision.Card.Localized = nil
+
+	if previous != nil {
+		revision.Changes = DiffCards(*previous, card)
+	}
+
+	return revision
+}
+
+type CardRevisionModel struct {
+	collection *mongo.Collection
+}
+
+func InitCardRevisionModel(d *db.Database) *CardRevisionModel {
+	collection := d.Collection("card_revisions")
+	indices := make([]mongo.IndexModel, 2)
+	indices[0] = mongo.IndexModel{
+		Keys: bson.D{{Key: "card_id", Value: 1}, {Key: "date_created", Value: -1}},
+	}
+	indices[1] = mongo.IndexModel{
+		Keys: bson.D{{Key: "version", Value: 1}},
+	}
+
+	_, err := collection.Indexes().CreateMany(
+		context.Background(),
+		indices,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewCardRevisionModel(collection)
+}
+
+func NewCardRevisionModel(c *mongo.Collection) *CardRevisionModel {
+	return &CardRevisionModel{
+		collection: c,
+	}
+}
+
+func (m *CardRevisionModel) SaveRevisions(revisions []CardRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Revisions replace the card's earlier one for the same version, so retrying a failed sync doesn't repeat them
+	operations := make([]mongo.WriteModel, len(revisions))
+	for i, revision := range revisions {
+		operations[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"card_id": revision.CardID, "version": revision.Version}).
+			SetReplacement(revision).
+			SetUpsert(true)
+	}
+
+	_, err := m.collection.BulkWrite(ctx, operations)
+	return err
+}
+
+func (m *CardRevisionModel) findRevisions(filter bson.M, sort bson.D) ([]*CardRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var revisions []*CardRevision
+	cur, err := m.collection.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close(ctx)
+
+	if err := cur.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+
+	if revisions == nil {
+		revisions = make([]*CardRevision, 0)
+	}
+
+	return revisions, nil
+}
+
+// GetCardHistory returns every recorded revision of the card, newest first
+func (m *CardRevisionModel) GetCardHistory(cardID string) ([]*CardRevision, error) {
+	return m.findRevisions(bson.M{"card_id": cardID}, bson.D{{Key: "date_created", Value: -1}})
+}
+
+// GetPatchChanges returns the revisions recorded for a Data Dragon version, ordered by card
+func (m *CardRevisionModel) GetPatchChanges(version string) ([]*CardRevision, error) {
+	return m.findRevisions(bson.M{"version": version}, bson.D{{Key: "card_id", Value: 1}})
+}
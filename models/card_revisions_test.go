@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+func TestNewCardRevision(t *testing.T) {
+	card := models.Card{ID: "01FR024", Name: "Ashe", Cost: 4}
+
+	revision := models.NewCardRevision(nil, card, "1.0.0")
+	assert.True(t, revision.New)
+	assert.Empty(t, revision.Changes)
+
+	changed := card
+	changed.Cost = 5
+	revision = models.NewCardRevision(&card, changed, "1.1.0")
+	assert.False(t, revision.New)
+	assert.Equal(t, "cost", revision.Changes[0].Field)
+	assert.Equal(t, 5, revision.Card.Cost)
+}
+
+func TestCardHistory(t *testing.T) {
+	card := models.Card{ID: "history_card", Name: "History", Cost: 1}
+	changed := card
+	changed.Cost = 2
+
+	revisions := []models.CardRevision{
+		models.NewCardRevision(nil, card, "1.0.0"),
+		models.NewCardRevision(&card, changed, "1.1.0"),
+	}
+	err := models.CardRevisions.SaveRevisions(revisions)
+	assert.Nil(t, err)
+
+	history, err := models.CardRevisions.GetCardHistory("history_card")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(history))
+
+	err = models.CardRevisions.SaveRevisions(revisions)
+	assert.Nil(t, err)
+	history, err = models.CardRevisions.GetCardHistory("history_card")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(history))
+
+	changes, err := models.CardRevisions.GetPatchChanges("1.1.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "cost", changes[0].Changes[0].Field)
+
+	changes, err = models.CardRevisions.GetPatchChanges("0.0.1")
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestAffectsGameplay(t *testing.T) {
+	card := models.Card{ID: "01FR024", Cost: 4, FlavorText: "Old"}
+
+	flavor := card
+	flavor.FlavorText = "New"
+	assert.False(t, models.NewCardRevision(&card, flavor, "1.1.0").AffectsGameplay())
+
+	art := card
+	art.ArtistName = "New Artist"
+	assert.False(t, models.NewCardRevision(&card, art, "1.1.0").AffectsGameplay())
+
+	nerfed := card
+	nerfed.Cost = 5
+	assert.True(t, models.NewCardRevision(&card, nerfed, "1.1.0").AffectsGameplay())
+
+	reworded := card
+	reworded.Description = "When I'm summoned, Frostbite the strongest enemy."
+	assert.True(t, models.NewCardRevision(&card, reworded, "1.1.0").AffectsGameplay())
+
+	leveled := card
+	leveled.LevelUpDescription = "I've seen 6 or more enemies with 0 Power."
+	assert.True(t, models.NewCardRevision(&card, leveled, "1.1.0").AffectsGameplay())
+
+	assert.False(t, models.NewCardRevision(nil, card, "1.1.0").AffectsGameplay())
+}
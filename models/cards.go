@@ -2,8 +2,11 @@ package models
 
 import (
 	"context"
-	"log"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -15,34 +18,96 @@ import (
 )
 
 type Card struct {
-	ID                    string   `json:"_id,omitempty" bson:"_id,omitempty"`
-	AssociatedCardRefs    []string `json:"associatedCardRefs" bson:"associatedCardRefs"`
-	Region                string   `json:"region" bson:"region"`
-	Regions               []string `json:"regions" bson:"regions"`
-	RegionRef             string   `json:"regionRef" bson:"regionRef"`
-	Attack                int      `json:"attack" bson:"attack"`
-	Cost                  int      `json:"cost" bson:"cost"`
-	Health                int      `json:"health" bson:"health"`
+	ID                    string              `json:"_id,omitempty" bson:"_id,omitempty"`
+	AssociatedCardRefs    []string            `json:"associatedCardRefs" bson:"associatedCardRefs"`
+	Region                string              `json:"region" bson:"region"`
+	Regions               []string            `json:"regions" bson:"regions"`
+	RegionRef             string              `json:"regionRef" bson:"regionRef"`
+	Attack                int                 `json:"attack" bson:"attack"`
+	Cost                  int                 `json:"cost" bson:"cost"`
+	Health                int                 `json:"health" bson:"health"`
+	Description           string              `json:"description" bson:"description"`
+	DescriptionRaw        string              `json:"descriptionRaw" bson:"descriptionRaw"`
+	LevelUpDescription    string              `json:"levelupDescription" bson:"levelupDescription"`
+	LevelUpDescriptionRaw string              `json:"levelupDescriptionRaw" bson:"levelupDescriptionRaw"`
+	FlavorText            string              `json:"flavorText" bson:"flavorText"`
+	ArtistName            string              `json:"artistName" bson:"artistName"`
+	Name                  string              `json:"name" bson:"name"`
+	CardCode              string              `json:"cardCode,omitempty" bson:"cardCode,omitempty"`
+	Keywords              []string            `json:"keywords" bson:"keywords"`
+	KeywordRefs           []string            `json:"keywordRefs" bson:"keywordRefs"`
+	SpellSpeed            string              `json:"spellSpeed" bson:"spellSpeed"`
+	SpellSpeedRef         string              `json:"spellSpeedRef" bson:"spellSpeedRef"`
+	Rarity                string              `json:"rarity" bson:"rarity"`
+	RarityRef             string              `json:"rarityRef" bson:"rarityRef"`
+	Subtype               string              `json:"subtype" bson:"subtype"`
+	Supertype             string              `json:"supertype" bson:"supertype"`
+	Type                  string              `json:"type" bson:"type"`
+	Collectible           bool                `json:"collectible" bson:"collectible"`
+	CardSet               int                 `json:"card_set" bson:"card_set"`
+	CardSubset            int                 `json:"card_subset,omitempty" bson:"card_subset,omitempty"`
+	Localized             map[string]CardText `json:"localized,omitempty" bson:"localized,omitempty"`
+}
+
+// DefaultLocale is the language of the text stored directly on a card
+const DefaultLocale = "en_us"
+
+// CardText is the translated text of a card in one locale
+type CardText struct {
+	Name                  string   `json:"name" bson:"name"`
 	Description           string   `json:"description" bson:"description"`
 	DescriptionRaw        string   `json:"descriptionRaw" bson:"descriptionRaw"`
 	LevelUpDescription    string   `json:"levelupDescription" bson:"levelupDescription"`
 	LevelUpDescriptionRaw string   `json:"levelupDescriptionRaw" bson:"levelupDescriptionRaw"`
 	FlavorText            string   `json:"flavorText" bson:"flavorText"`
-	ArtistName            string   `json:"artistName" bson:"artistName"`
-	Name                  string   `json:"name" bson:"name"`
-	CardCode              string   `json:"cardCode,omitempty" bson:"cardCode,omitempty"`
 	Keywords              []string `json:"keywords" bson:"keywords"`
-	KeywordRefs           []string `json:"keywordRefs" bson:"keywordRefs"`
 	SpellSpeed            string   `json:"spellSpeed" bson:"spellSpeed"`
-	SpellSpeedRef         string   `json:"spellSpeedRef" bson:"spellSpeedRef"`
 	Rarity                string   `json:"rarity" bson:"rarity"`
-	RarityRef             string   `json:"rarityRef" bson:"rarityRef"`
 	Subtype               string   `json:"subtype" bson:"subtype"`
 	Supertype             string   `json:"supertype" bson:"supertype"`
 	Type                  string   `json:"type" bson:"type"`
-	Collectible           bool     `json:"collectible" bson:"collectible"`
-	CardSet               int      `json:"card_set" bson:"card_set"`
-	CardSubset            int      `json:"card_subset,omitempty" bson:"card_subset,omitempty"`
+}
+
+func (c Card) Text() CardText {
+	return CardText{
+		Name:                  c.Name,
+		Description:           c.Description,
+		DescriptionRaw:        c.DescriptionRaw,
+		LevelUpDescription:    c.LevelUpDescription,
+		LevelUpDescriptionRaw: c.LevelUpDescriptionRaw,
+		FlavorText:            c.FlavorText,
+		Keywords:              c.Keywords,
+		SpellSpeed:            c.SpellSpeed,
+		Rarity:                c.Rarity,
+		Subtype:               c.Subtype,
+		Supertype:             c.Supertype,
+		Type:                  c.Type,
+	}
+}
+
+// Localize returns the card with its text in the locale, or in the default locale if it has no translation.
+// The returned card does not carry the other translations, so it is cheap to send.
+func (c Card) Localize(locale string) Card {
+	text, ok := c.Localized[locale]
+	c.Localized = nil
+	if !ok {
+		return c
+	}
+
+	c.Name = text.Name
+	c.Description = text.Description
+	c.DescriptionRaw = text.DescriptionRaw
+	c.LevelUpDescription = text.LevelUpDescription
+	c.LevelUpDescriptionRaw = text.LevelUpDescriptionRaw
+	c.FlavorText = text.FlavorText
+	c.Keywords = text.Keywords
+	c.SpellSpeed = text.SpellSpeed
+	c.Rarity = text.Rarity
+	c.Subtype = text.Subtype
+	c.Supertype = text.Supertype
+	c.Type = text.Type
+
+	return c
 }
 
 func (c Card) Compare(b Card) bool {
@@ -58,9 +123,63 @@ func (c Card) CardNumber() int {
 	return cardNum
 }
 
+// Faction is the card's canonical faction, taken from its card code. Cards with codes the registry doesn't
+// know fall back to looking up their region ref and then their region's display name.
+func (c Card) Faction() (deck_encoder.FactionInfo, bool) {
+	code := c.CardCode
+	if len(code) == 0 {
+		code = c.ID
+	}
+	if len(code) >= 4 {
+		if faction, ok := deck_encoder.FactionByCode(code[2:4]); ok {
+			return faction, true
+		}
+	}
+
+	if faction, ok := deck_encoder.FactionByRegion(c.RegionRef); ok {
+		return faction, true
+	}
+	return deck_encoder.FactionByRegion(c.Region)
+}
+
+// PrimaryRegion is the display name of the card's canonical faction
+func (c Card) PrimaryRegion() string {
+	if faction, ok := c.Faction(); ok {
+		return faction.Name
+	}
+	return c.Region
+}
+
+// IsRuneterra reports whether the card is from the Runeterra faction. Runeterra cards can be played in any deck
+// without taking up one of its regions.
+func (c Card) IsRuneterra() bool {
+	faction, ok := c.Faction()
+	return ok && faction.Code == deck_encoder.RUNETERRA
+}
+
+// RegionOptions lists every region the card can be played in, starting with its primary region.
+// Multi-region cards list more than one.
+func (c Card) RegionOptions() []string {
+	options := []string{c.PrimaryRegion()}
+	for _, region := range c.Regions {
+		if faction, ok := deck_encoder.FactionByRegion(region); ok {
+			region = faction.Name
+		}
+		if !containsRegion(options, region) {
+			options = append(options, region)
+		}
+	}
+	return options
+}
+
 func (c Card) ToEncodableCard() deck_encoder.Card {
+	factionID := -1
+	if faction, ok := c.Faction(); ok {
+		factionID = faction.ID
+	}
+
 	return deck_encoder.Card{
-		Faction: deck_encoder.FactionNumberFromName(c.Region),
+		Faction: factionID,
 		Set:     c.CardSet,
 		Number:  c.CardNumber(),
 	}
@@ -73,9 +192,62 @@ func (c Card) ToEncodableCardInDeck(quantity int) deck_encoder.CardInDeck {
 	}
 }
 
+// cardMissLifetime is how long an unknown card code is remembered before the database is asked about it again
+const cardMissLifetime = 10 * time.Minute
+
+// maxCardMisses caps how many unknown card codes are remembered, since anyone can ask for made up codes
+const maxCardMisses = 10000
+
+// cardIndex maps lookup keys to positions in the cached cards slice. Region and keyword keys are lower case.
+type cardIndex struct {
+	byCode    map[string]int
+	byRegion  map[string][]int
+	bySet     map[int][]int
+	byKeyword map[string][]int
+}
+
+func newCardIndex(cards []Card) cardIndex {
+	index := cardIndex{
+		byCode:    make(map[string]int, len(cards)),
+		byRegion:  make(map[string][]int),
+		bySet:     make(map[int][]int),
+		byKeyword: make(map[string][]int),
+	}
+
+	for i, card := range cards {
+		index.byCode[card.CardCode] = i
+		index.bySet[card.CardSet] = append(index.bySet[card.CardSet], i)
+		addIndexKeys(index.byRegion, i, append([]string{card.Region, card.RegionRef}, card.Regions...))
+		addIndexKeys(index.byKeyword, i, append(card.Keywords, card.KeywordRefs...))
+	}
+
+	return index
+}
+
+// addIndexKeys adds the card position under each distinct key, ignoring case
+func addIndexKeys(index map[string][]int, position int, keys []string) {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		if len(key) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		index[key] = append(index[key], position)
+	}
+}
+
+// cardSnapshot is an immutable view of the cached cards. It is replaced as a whole, never modified.
+type cardSnapshot struct {
+	cards []Card
+	index cardIndex
+}
+
 type CardModel struct {
 	collection *mongo.Collection
-	Cards      []Card
+	snapshot   atomic.Value
+	misses     map[string]time.Time
+	missLock   sync.Mutex
 }
 
 func InitCardModel(d *db.Database) *CardModel {
@@ -86,10 +258,11 @@ func InitCardModel(d *db.Database) *CardModel {
 }
 
 func NewCardModel(collection *mongo.Collection) *CardModel {
-	return &CardModel{
+	m := &CardModel{
 		collection: collection,
-		Cards:      make([]Card, 0),
 	}
+	m.SetCards(make([]Card, 0))
+	return m
 }
 
 func (m *CardModel) CacheCards() error {
@@ -109,25 +282,146 @@ func (m *CardModel) CacheCards() error {
 		return err
 	}
 
-	m.Cards = data
+	m.SetCards(data)
 
 	return nil
 }
 
+// SetCards builds a new snapshot from the cards and swaps it in, so readers see either the old cards or the new ones
+func (m *CardModel) SetCards(cards []Card) {
+	m.snapshot.Store(&cardSnapshot{
+		cards: cards,
+		index: newCardIndex(cards),
+	})
+
+	m.missLock.Lock()
+	m.misses = make(map[string]time.Time)
+	m.missLock.Unlock()
+}
+
+func (m *CardModel) current() *cardSnapshot {
+	return m.snapshot.Load().(*cardSnapshot)
+}
+
+// GetAll returns the cached cards. The slice is shared between callers and must not be modified.
 func (m *CardModel) GetAll() []Card {
-	return m.Cards
+	return m.current().cards
 }
 
-func (m *CardModel) GetCard(cardCode string) *Card {
-	cards := m.GetAll()
-	for _, card := range cards {
-		if card.CardCode == cardCode {
-			return &card
+// cardsAt copies the snapshot's cards at the given positions
+func (s *cardSnapshot) cardsAt(positions []int) []Card {
+	cards := make([]Card, len(positions))
+	for i, position := range positions {
+		cards[i] = s.cards[position]
+	}
+	return cards
+}
+
+// candidates narrows the cards down to the ones in the query's regions and sets that have all of its keywords,
+// keeping their cached order. Every card is a candidate when the query filters on none of those.
+func (s *cardSnapshot) candidates(q CardQuery) []Card {
+	// positions is nil until a filter narrows it down. Each filter keeps the positions in any of its lists.
+	var positions map[int]bool
+	narrow := func(lists ...[]int) {
+		found := make(map[int]bool)
+		for _, list := range lists {
+			for _, position := range list {
+				if positions == nil || positions[position] {
+					found[position] = true
+				}
+			}
+		}
+		positions = found
+	}
+
+	if len(q.Regions) > 0 {
+		lists := make([][]int, len(q.Regions))
+		for i, region := range q.Regions {
+			lists[i] = s.index.byRegion[strings.ToLower(region)]
+		}
+		narrow(lists...)
+	}
+	if len(q.Sets) > 0 {
+		lists := make([][]int, len(q.Sets))
+		for i, set := range q.Sets {
+			lists[i] = s.index.bySet[set]
 		}
+		narrow(lists...)
+	}
+	for _, keyword := range q.Keywords {
+		narrow(s.index.byKeyword[strings.ToLower(keyword)])
+	}
+
+	if positions == nil {
+		return s.cards
+	}
+	sorted := make([]int, 0, len(positions))
+	for position := range positions {
+		sorted = append(sorted, position)
+	}
+	sort.Ints(sorted)
+
+	return s.cardsAt(sorted)
+}
+
+func (m *CardModel) GetCardsByRegion(region string) []Card {
+	snapshot := m.current()
+	return snapshot.cardsAt(snapshot.index.byRegion[strings.ToLower(region)])
+}
+
+func (m *CardModel) GetCardsBySet(set int) []Card {
+	snapshot := m.current()
+	return snapshot.cardsAt(snapshot.index.bySet[set])
+}
+
+func (m *CardModel) GetCardsByKeyword(keyword string) []Card {
+	snapshot := m.current()
+	return snapshot.cardsAt(snapshot.index.byKeyword[strings.ToLower(keyword)])
+}
+
+func (m *CardModel) recentlyMissed(cardCode string) bool {
+	m.missLock.Lock()
+	defer m.missLock.Unlock()
+
+	missed, ok := m.misses[cardCode]
+	return ok && time.Since(missed) < cardMissLifetime
+}
+
+// recordMiss remembers an unknown card code. When the cache is full expired misses are dropped,
+// and if that isn't enough it starts over.
+func (m *CardModel) recordMiss(cardCode string) {
+	m.missLock.Lock()
+	defer m.missLock.Unlock()
+
+	if len(m.misses) >= maxCardMisses {
+		for code, missed := range m.misses {
+			if time.Since(missed) >= cardMissLifetime {
+				delete(m.misses, code)
+			}
+		}
+		if len(m.misses) >= maxCardMisses {
+			m.misses = make(map[string]time.Time)
+		}
+	}
+
+	m.misses[cardCode] = time.Now()
+}
+
+// GetCard returns the card from the cache, falling back to the database for codes that have not recently been missing from it
+func (m *CardModel) GetCard(cardCode string) *Card {
+	snapshot := m.current()
+	if position, ok := snapshot.index.byCode[cardCode]; ok {
+		card := snapshot.cards[position]
+		return &card
+	}
+
+	if m.collection == nil || m.recentlyMissed(cardCode) {
+		return nil
 	}
 
 	cardInDB, err := m.GetCardFromDB(cardCode)
 	if err != nil {
+		m.recordMiss(cardCode)
 		return nil
 	}
 
@@ -144,7 +438,8 @@ func (m *CardModel) GetCardFromDB(cardCode string) (*Card, error) {
 	return &card, nil
 }
 
-func (m *CardModel) UpdateCards(cards []Card) {
+// UpdateCards upserts the cards and reloads the cache from the database
+func (m *CardModel) UpdateCards(cards []Card) error {
 	var operations []mongo.WriteModel
 
 	for _, card := range cards {
@@ -163,7 +458,161 @@ func (m *CardModel) UpdateCards(cards []Card) {
 	bulkContext := context.Background()
 	_, err := m.collection.BulkWrite(bulkContext, operations, &bulkOption)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	return m.CacheCards()
+}
+
+// CardQuery filters, sorts and pages the cached cards. Empty filters match every card.
+type CardQuery struct {
+	Regions     []string `json:"regions" bson:"regions"`
+	MinCost     *int     `json:"minCost" bson:"minCost"`
+	MaxCost     *int     `json:"maxCost" bson:"maxCost"`
+	Rarities    []string `json:"rarities" bson:"rarities"`
+	Types       []string `json:"types" bson:"types"`
+	Supertypes  []string `json:"supertypes" bson:"supertypes"`
+	Keywords    []string `json:"keywords" bson:"keywords"`
+	Sets        []int    `json:"sets" bson:"sets"`
+	Collectible *bool    `json:"collectible" bson:"collectible"`
+	Search      string   `json:"search" bson:"search"`
+	Locale      string   `json:"locale" bson:"locale"`
+	Sorting     string   `json:"sorting" bson:"sorting"`
+	SortAsc     int      `json:"sortAsc" bson:"sortAsc"`
+	Page        int      `json:"page" bson:"page"`
+	Limit       int      `json:"limit" bson:"limit"`
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
 	}
-	go m.CacheCards()
+	return false
+}
+
+// matchesAny reports whether any of the card's values is one of the wanted values
+func matchesAny(wanted []string, values ...string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if len(value) > 0 && containsFold(wanted, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q CardQuery) Matches(card Card) bool {
+	regions := append([]string{card.Region, card.RegionRef}, card.Regions...)
+	if !matchesAny(q.Regions, regions...) {
+		return false
+	}
+	if q.MinCost != nil && card.Cost < *q.MinCost {
+		return false
+	}
+	if q.MaxCost != nil && card.Cost > *q.MaxCost {
+		return false
+	}
+	if !matchesAny(q.Rarities, card.Rarity, card.RarityRef) {
+		return false
+	}
+	if !matchesAny(q.Types, card.Type) {
+		return false
+	}
+	if !matchesAny(q.Supertypes, card.Supertype) {
+		return false
+	}
+	for _, keyword := range q.Keywords {
+		if !containsFold(card.Keywords, keyword) && !containsFold(card.KeywordRefs, keyword) {
+			return false
+		}
+	}
+	if len(q.Sets) > 0 && !containsSet(q.Sets, card.CardSet) {
+		return false
+	}
+	if q.Collectible != nil && card.Collectible != *q.Collectible {
+		return false
+	}
+	if len(q.Search) > 0 {
+		search := strings.ToLower(q.Search)
+		text := strings.ToLower(card.Name + "\n" + card.DescriptionRaw + "\n" + card.LevelUpDescriptionRaw)
+		if localized, ok := card.Localized[q.Locale]; ok {
+			text += strings.ToLower("\n" + localized.Name + "\n" + localized.DescriptionRaw + "\n" + localized.LevelUpDescriptionRaw)
+		}
+		if !strings.Contains(text, search) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsSet(sets []int, set int) bool {
+	for _, s := range sets {
+		if s == set {
+			return true
+		}
+	}
+	return false
+}
+
+// less orders cards by the query's sort field, falling back to the card code so pages are stable
+func (q CardQuery) less(a, b Card) bool {
+	var compare int
+	switch q.Sorting {
+	case "name":
+		compare = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case "cost":
+		compare = a.Cost - b.Cost
+	case "attack":
+		compare = a.Attack - b.Attack
+	case "health":
+		compare = a.Health - b.Health
+	case "set":
+		compare = a.CardSet - b.CardSet
+	}
+
+	if compare == 0 {
+		compare = strings.Compare(a.ID, b.ID)
+	}
+	if q.SortAsc == -1 {
+		return compare > 0
+	}
+	return compare < 0
+}
+
+// SearchCards returns the requested page of cached cards matching the query in the query's locale,
+// along with the total number of matches
+func (m *CardModel) SearchCards(q CardQuery) ([]Card, int) {
+	matches := make([]Card, 0)
+	for _, card := range m.current().candidates(q) {
+		if q.Matches(card) {
+			matches = append(matches, card.Localize(q.Locale))
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return q.less(matches[i], matches[j])
+	})
+
+	total := len(matches)
+	if q.Limit <= 0 {
+		return matches, total
+	}
+
+	// The page is compared before multiplying, so huge pages can't overflow
+	if total == 0 || q.Page < 0 || q.Page > (total-1)/q.Limit {
+		return make([]Card, 0), total
+	}
+	start := q.Page * q.Limit
+	end := total
+	if q.Limit < total-start {
+		end = start + q.Limit
+	}
+
+	return matches[start:end], total
 }
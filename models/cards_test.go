@@ -25,14 +25,15 @@ func TestCacheCards(t *testing.T) {
 	err := model.CacheCards()
 
 	assert.Nil(t, err)
-	assert.NotEmpty(t, model.Cards)
+	assert.NotEmpty(t, model.GetAll())
 }
 
 func TestGetAll(t *testing.T) {
 	cards := make([]models.Card, 1)
 	cards[0] = models.Card{ID: "test"}
 
-	model := models.CardModel{Cards: cards}
+	model := models.NewCardModel(nil)
+	model.SetCards(cards)
 
 	expected := cards[0].ID
 	received := model.GetAll()[0].ID
@@ -40,6 +41,83 @@ func TestGetAll(t *testing.T) {
 	assert.Equal(t, expected, received)
 }
 
+func TestCardIndex(t *testing.T) {
+	model := searchableCards()
+
+	card := model.GetCard("02BW032")
+	assert.Equal(t, "Gangplank", card.Name)
+
+	card.Name = "Changed"
+	assert.Equal(t, "Gangplank", model.GetCard("02BW032").Name)
+
+	assert.Nil(t, model.GetCard("doesntexist"))
+	assert.Equal(t, 2, len(model.GetCardsByRegion("bilgewater")))
+	assert.Equal(t, 2, len(model.GetCardsBySet(1)))
+	assert.Equal(t, "Ashe", model.GetCardsByKeyword("FROSTBITE")[0].Name)
+	assert.Empty(t, model.GetCardsByKeyword("Overwhelm"))
+}
+
+func TestLocalizeCard(t *testing.T) {
+	card := models.Card{
+		ID:        "01FR024",
+		Name:      "Ashe",
+		Keywords:  []string{"Frostbite"},
+		RegionRef: "Freljord",
+		Localized: map[string]models.CardText{
+			"ko_kr": {Name: "애쉬", Keywords: []string{"빙결"}},
+		},
+	}
+
+	localized := card.Localize("ko_kr")
+	assert.Equal(t, "애쉬", localized.Name)
+	assert.Equal(t, []string{"빙결"}, localized.Keywords)
+	assert.Equal(t, "Freljord", localized.RegionRef)
+	assert.Nil(t, localized.Localized)
+	assert.Equal(t, "Ashe", card.Name)
+
+	fallback := card.Localize("fr_fr")
+	assert.Equal(t, "Ashe", fallback.Name)
+	assert.Nil(t, fallback.Localized)
+
+	model := models.NewCardModel(nil)
+	model.SetCards([]models.Card{card})
+
+	received, total := model.SearchCards(models.CardQuery{Search: "애쉬", Locale: "ko_kr"})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "애쉬", received[0].Name)
+}
+
+func TestSetCardsConcurrently(t *testing.T) {
+	model := searchableCards()
+	cards := model.GetAll()
+	done := make(chan bool)
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			model.SetCards(cards[:i%len(cards)+1])
+		}
+		done <- true
+	}()
+
+	for i := 0; i < 100; i++ {
+		card := model.GetCard("01FR024")
+		assert.Equal(t, "Ashe", card.Name)
+		model.SearchCards(models.CardQuery{Regions: []string{"Ionia"}})
+	}
+	<-done
+
+	assert.Equal(t, len(cards), len(model.GetAll()))
+}
+
+func TestGetCardMisses(t *testing.T) {
+	database := InitializeDatabase()
+	model := models.NewCardModel(database.Collection("cards"))
+
+	assert.Nil(t, model.GetCard("doesntexist"))
+	assert.Nil(t, model.GetCard("doesntexist"))
+	assert.Equal(t, "01IO012", model.GetCard("01IO012").ID)
+}
+
 func TestGetCard(t *testing.T) {
 	expected := "01IO012"
 	received := models.Cards.GetCard("01IO012").ID
@@ -66,10 +144,113 @@ func TestUpdateCards(t *testing.T) {
 	database := InitializeDatabase()
 
 	model := models.NewCardModel(database.Collection("cards"))
-	model.UpdateCards(cardUpdates)
+	err := model.UpdateCards(cardUpdates)
+	assert.Nil(t, err)
 
 	updatedCard, err := model.GetCardFromDB(cardUpdates[0].ID)
 
 	assert.Nil(t, err)
 	assert.Equal(t, cardUpdates[0].Region, updatedCard.Region)
 }
+
+func searchableCards() *models.CardModel {
+	cards := []models.Card{
+		{ID: "01FR024", CardCode: "01FR024", Name: "Ashe", Region: "Freljord", RegionRef: "Freljord", Cost: 4, Rarity: "Champion", Supertype: "Champion", Type: "Unit", Keywords: []string{"Frostbite"}, CardSet: 1, Collectible: true},
+		{ID: "01IO012", CardCode: "01IO012", Name: "Twin Disciplines", Region: "Ionia", RegionRef: "Ionia", Cost: 2, Rarity: "Common", Type: "Spell", DescriptionRaw: "Give an ally +2|+0 or +0|+3 this round.", CardSet: 1, Collectible: true},
+		{ID: "02BW032", CardCode: "02BW032", Name: "Gangplank", Region: "Bilgewater", RegionRef: "Bilgewater", Cost: 5, Rarity: "Champion", Supertype: "Champion", Type: "Unit", CardSet: 2, Collectible: true},
+		{ID: "02BW032T1", CardCode: "02BW032T1", Name: "Powder Keg", Region: "Bilgewater", RegionRef: "Bilgewater", Cost: 0, Type: "Spell", CardSet: 2},
+	}
+
+	model := models.NewCardModel(nil)
+	model.SetCards(cards)
+
+	return model
+}
+
+func TestSearchCards(t *testing.T) {
+	model := searchableCards()
+	zero := 0
+	four := 4
+	collectible := true
+
+	received, total := model.SearchCards(models.CardQuery{})
+	assert.Equal(t, 4, total)
+	assert.Equal(t, "01FR024", received[0].ID)
+
+	received, total = model.SearchCards(models.CardQuery{Regions: []string{"bilgewater"}})
+	assert.Equal(t, 2, total)
+
+	received, total = model.SearchCards(models.CardQuery{MaxCost: &zero})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "02BW032T1", received[0].ID)
+
+	received, total = model.SearchCards(models.CardQuery{MinCost: &four, Supertypes: []string{"Champion"}})
+	assert.Equal(t, 2, total)
+
+	received, total = model.SearchCards(models.CardQuery{Keywords: []string{"frostbite"}})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "Ashe", received[0].Name)
+
+	received, total = model.SearchCards(models.CardQuery{Sets: []int{2}, Collectible: &collectible})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "Gangplank", received[0].Name)
+
+	received, total = model.SearchCards(models.CardQuery{Search: "ALLY", Types: []string{"Spell"}, Rarities: []string{"Common"}})
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "Twin Disciplines", received[0].Name)
+
+	received, total = model.SearchCards(models.CardQuery{Regions: []string{"Ionia", "freljord"}, Sets: []int{1, 2}})
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "01FR024", received[0].ID)
+	assert.Equal(t, "01IO012", received[1].ID)
+
+	_, total = model.SearchCards(models.CardQuery{Regions: []string{"Bilgewater"}, Sets: []int{1}})
+	assert.Equal(t, 0, total)
+
+	_, total = model.SearchCards(models.CardQuery{Keywords: []string{"Frostbite", "Overwhelm"}})
+	assert.Equal(t, 0, total)
+
+	_, total = model.SearchCards(models.CardQuery{Regions: []string{"Shurima"}})
+	assert.Equal(t, 0, total)
+}
+
+func TestSearchCardsSortAndPage(t *testing.T) {
+	model := searchableCards()
+
+	received, total := model.SearchCards(models.CardQuery{Sorting: "cost", SortAsc: -1, Limit: 2})
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 2, len(received))
+	assert.Equal(t, "Gangplank", received[0].Name)
+	assert.Equal(t, "Ashe", received[1].Name)
+
+	received, _ = model.SearchCards(models.CardQuery{Sorting: "cost", SortAsc: -1, Limit: 2, Page: 1})
+	assert.Equal(t, "Twin Disciplines", received[0].Name)
+	assert.Equal(t, "Powder Keg", received[1].Name)
+
+	received, _ = model.SearchCards(models.CardQuery{Sorting: "name", Limit: 2, Page: 5})
+	assert.Empty(t, received)
+
+	received, _ = model.SearchCards(models.CardQuery{Limit: 2, Page: (1 << 62) + 1})
+	assert.Empty(t, received)
+
+	received, _ = model.SearchCards(models.CardQuery{Limit: 1 << 62, Page: 1})
+	assert.Empty(t, received)
+
+	received, _ = model.SearchCards(models.CardQuery{Limit: 1 << 62})
+	assert.Equal(t, 4, len(received))
+}
+
+func TestDiffCards(t *testing.T) {
+	old := models.Card{ID: "01FR024", Name: "Ashe", Cost: 4, Keywords: []string{"Frostbite"}}
+	new := old
+	new.Cost = 5
+	new.Keywords = []string{"Frostbite", "Overwhelm"}
+	new.Localized = map[string]models.CardText{"ko_kr": {Name: "애쉬"}}
+
+	changes := models.DiffCards(old, new)
+
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, models.FieldChange{Field: "cost", Old: 4, New: 5}, changes[0])
+	assert.Equal(t, "keywords", changes[1].Field)
+	assert.Empty(t, models.DiffCards(old, old))
+}
@@ -6,10 +6,20 @@ var Cards *CardModel
 var Decks *DeckModel
 var Users *UserModel
 var Archetypes *ArchetypesModel
+var Sessions *SessionModel
+var UserTokens *UserTokenModel
+var Globals *GlobalsModel
+var CardRevisions *CardRevisionModel
+var AffectedDecks *AffectedDeckModel
 
 func InitModels(d *db.Database) {
 	Cards = InitCardModel(d)
 	Decks = InitDeckModel(d)
 	Users = InitUserModel(d)
 	Archetypes = InitArchetypesModel(d)
+	Sessions = InitSessionModel(d)
+	UserTokens = InitUserTokenModel(d)
+	Globals = InitGlobalsModel(d)
+	CardRevisions = InitCardRevisionModel(d)
+	AffectedDecks = InitAffectedDeckModel(d)
 }
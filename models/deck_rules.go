@@ -0,0 +1,199 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+)
+
+// ViolationCode identifies a broken deckbuilding rule, so the deckbuilder can highlight it
+type ViolationCode string
+
+const (
+	ViolationInvalidQuantity  ViolationCode = "invalid_quantity"
+	ViolationUnknownCard      ViolationCode = "unknown_card"
+	ViolationDeckSize         ViolationCode = "deck_size"
+	ViolationEmptyDeck        ViolationCode = "empty_deck"
+	ViolationTooManyChampions ViolationCode = "too_many_champions"
+	ViolationTooManyCopies    ViolationCode = "too_many_copies"
+	ViolationNotCollectible   ViolationCode = "not_collectible"
+	ViolationSetNotAllowed    ViolationCode = "set_not_allowed"
+	ViolationTooManyRegions   ViolationCode = "too_many_regions"
+)
+
+// DeckViolation is one broken rule. CardID is set when the rule was broken by a particular card.
+type DeckViolation struct {
+	Code    ViolationCode `json:"code"`
+	Message string        `json:"message"`
+	CardID  string        `json:"cardId,omitempty"`
+}
+
+// DeckFormat is the set of deckbuilding rules a deck is checked against
+type DeckFormat struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	DeckSize     int    `json:"deckSize"`
+	MaxCopies    int    `json:"maxCopies"`
+	MaxChampions int    `json:"maxChampions"`
+	MaxRegions   int    `json:"maxRegions"`
+	// Sets limits the card sets that can be used. Every set is allowed when it is empty.
+	Sets []int `json:"sets,omitempty"`
+}
+
+var (
+	FormatStandard  = DeckFormat{ID: "standard", Name: "Standard", DeckSize: 40, MaxCopies: 3, MaxChampions: 6, MaxRegions: MaxDeckRegions}
+	FormatEternal   = DeckFormat{ID: "eternal", Name: "Eternal", DeckSize: 40, MaxCopies: 3, MaxChampions: 6, MaxRegions: MaxDeckRegions}
+	FormatSingleton = DeckFormat{ID: "singleton", Name: "Singleton", DeckSize: 40, MaxCopies: 1, MaxChampions: 6, MaxRegions: MaxDeckRegions}
+	FormatGauntlet  = DeckFormat{ID: "gauntlet", Name: "Gauntlet", DeckSize: 40, MaxCopies: 3, MaxChampions: 6, MaxRegions: 3}
+)
+
+var (
+	formatLock  sync.RWMutex
+	deckFormats = map[string]DeckFormat{
+		FormatStandard.ID:  FormatStandard,
+		FormatEternal.ID:   FormatEternal,
+		FormatSingleton.ID: FormatSingleton,
+		FormatGauntlet.ID:  FormatGauntlet,
+	}
+)
+
+// RegisterDeckFormat adds a format, or replaces the one with the same ID
+func RegisterDeckFormat(format DeckFormat) {
+	formatLock.Lock()
+	defer formatLock.Unlock()
+
+	deckFormats[strings.ToLower(format.ID)] = format
+}
+
+// DeckFormatByID finds a format. Decks without one are Standard.
+func DeckFormatByID(id string) (DeckFormat, bool) {
+	if len(id) == 0 {
+		id = FormatStandard.ID
+	}
+
+	formatLock.RLock()
+	defer formatLock.RUnlock()
+
+	format, ok := deckFormats[strings.ToLower(id)]
+	return format, ok
+}
+
+// DeckFormats lists every format ordered by ID
+func DeckFormats() []DeckFormat {
+	formatLock.RLock()
+	defer formatLock.RUnlock()
+
+	formats := make([]DeckFormat, 0, len(deckFormats))
+	for _, format := range deckFormats {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i].ID < formats[j].ID })
+
+	return formats
+}
+
+func (f DeckFormat) allowsSet(set int) bool {
+	if len(f.Sets) == 0 {
+		return true
+	}
+	for _, allowed := range f.Sets {
+		if allowed == set {
+			return true
+		}
+	}
+	return false
+}
+
+// DeckFormat is the format the deck was built for, falling back to Standard if it is unknown
+func (d Deck) DeckFormat() DeckFormat {
+	if format, ok := DeckFormatByID(d.Format); ok {
+		return format
+	}
+	return FormatStandard
+}
+
+// Violations checks the deck against every rule of the format. The deck size and champion limit only apply
+// when publishing, and sandbox decks can be published at any size.
+func (d Deck) Violations(format DeckFormat, publish, sandbox bool) []DeckViolation {
+	violations := []DeckViolation{}
+	add := func(code ViolationCode, cardID, message string, args ...interface{}) {
+		violations = append(violations, DeckViolation{Code: code, Message: fmt.Sprintf(message, args...), CardID: cardID})
+	}
+
+	// Cards listed more than once are counted together, so splitting a card across entries can't dodge the copy limit
+	quantities := map[string]int{}
+	cardIDs := []string{}
+	for _, cardQuant := range d.Cards {
+		if cardQuant.Quantity < 1 {
+			add(ViolationInvalidQuantity, cardQuant.CardID, "Card quantities must be at least 1")
+			continue
+		}
+		if _, ok := quantities[cardQuant.CardID]; !ok {
+			cardIDs = append(cardIDs, cardQuant.CardID)
+		}
+		quantities[cardQuant.CardID] += cardQuant.Quantity
+	}
+
+	cardCount, championCount := 0, 0
+	for _, cardID := range cardIDs {
+		cardCount += quantities[cardID]
+
+		card := Cards.GetCard(cardID)
+		if card == nil {
+			add(ViolationUnknownCard, cardID, "Card with ID %s does not exist", cardID)
+			continue
+		}
+		if card.Supertype == "Champion" {
+			championCount += quantities[cardID]
+		}
+	}
+
+	if publish && !sandbox && cardCount != format.DeckSize {
+		add(ViolationDeckSize, "", "Deck must include %d cards to be published", format.DeckSize)
+	}
+
+	if cardCount == 0 {
+		add(ViolationEmptyDeck, "", "Deck must include at least 1 card")
+	}
+
+	if publish && championCount > format.MaxChampions {
+		add(ViolationTooManyChampions, "", "Deck can only contain at most %d Champion Cards", format.MaxChampions)
+	}
+
+	for _, cardID := range cardIDs {
+		if quantities[cardID] > format.MaxCopies {
+			add(ViolationTooManyCopies, cardID, "Deck can only contain, at most, %d of any individual card", format.MaxCopies)
+		}
+	}
+
+	for _, cardID := range cardIDs {
+		card := Cards.GetCard(cardID)
+		if card == nil {
+			continue
+		}
+		if !card.Collectible {
+			add(ViolationNotCollectible, card.ID, "%s can't be used in decks", card.Name)
+		}
+		if !format.allowsSet(card.CardSet) {
+			add(ViolationSetNotAllowed, card.ID, "%s isn't in a set allowed in %s", card.Name, format.Name)
+		}
+	}
+
+	if _, ok := d.minimalRegions(format.MaxRegions); !ok {
+		add(ViolationTooManyRegions, "", "Deck can only contain cards from %d regions", format.MaxRegions)
+	}
+
+	return violations
+}
+
+// NewDeckRulesError reports the violations as an invalid deck, using the first one's message
+func NewDeckRulesError(violations []DeckViolation) *types.InvalidDeckError {
+	return &types.InvalidDeckError{
+		Err:     errors.New(violations[0].Message),
+		Details: violations,
+	}
+}
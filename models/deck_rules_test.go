@@ -0,0 +1,191 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+)
+
+func useRulesCards() func() {
+	savedCards := models.Cards
+
+	models.Cards = models.NewCardModel(nil)
+	models.Cards.SetCards([]models.Card{
+		{ID: "01DE001", CardCode: "01DE001", Name: "Vanguard Defender", Region: "Demacia", CardSet: 1, Collectible: true},
+		{ID: "01DE012", CardCode: "01DE012", Name: "Garen", Region: "Demacia", Supertype: "Champion", CardSet: 1, Collectible: true},
+		{ID: "01FR001", CardCode: "01FR001", Name: "Omen Hawk", Region: "Freljord", CardSet: 1, Collectible: true},
+		{ID: "01IO001", CardCode: "01IO001", Name: "Zed", Region: "Ionia", CardSet: 1, Collectible: true},
+		{ID: "01DE002", CardCode: "01DE002", Name: "Token", Region: "Demacia", CardSet: 1},
+		{ID: "04DE001", CardCode: "04DE001", Name: "New Card", Region: "Demacia", CardSet: 4, Collectible: true},
+		{ID: "06RU002", CardCode: "06RU002", Name: "Bard", Region: "Runeterra", Supertype: "Champion", CardSet: 6, Collectible: true},
+	})
+
+	return func() { models.Cards = savedCards }
+}
+
+func violationCodes(violations []models.DeckViolation) []models.ViolationCode {
+	codes := []models.ViolationCode{}
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func TestDeckViolations(t *testing.T) {
+	defer useRulesCards()()
+
+	deck := models.Deck{Cards: []models.CardQuantity{
+		{CardID: "01DE012", Quantity: 7},
+		{CardID: "01DE002", Quantity: 1},
+		{CardID: "01FR001", Quantity: 1},
+		{CardID: "01IO001", Quantity: 1},
+		{CardID: "missing", Quantity: 1},
+	}}
+
+	violations := deck.Violations(models.FormatStandard, true, false)
+
+	assert.Equal(t, []models.ViolationCode{
+		models.ViolationUnknownCard,
+		models.ViolationDeckSize,
+		models.ViolationTooManyChampions,
+		models.ViolationTooManyCopies,
+		models.ViolationNotCollectible,
+		models.ViolationTooManyRegions,
+	}, violationCodes(violations))
+	assert.Equal(t, "Card with ID missing does not exist", violations[0].Message)
+	assert.Equal(t, "missing", violations[0].CardID)
+	assert.Equal(t, "01DE012", violations[3].CardID)
+
+	violations = deck.Violations(models.FormatGauntlet, false, false)
+	assert.Equal(t, []models.ViolationCode{
+		models.ViolationUnknownCard,
+		models.ViolationTooManyCopies,
+		models.ViolationNotCollectible,
+	}, violationCodes(violations))
+
+	assert.Equal(t, []models.ViolationCode{models.ViolationEmptyDeck}, violationCodes(models.Deck{}.Violations(models.FormatStandard, false, false)))
+}
+
+func TestDeckViolationsDuplicateEntries(t *testing.T) {
+	defer useRulesCards()()
+
+	deck := models.Deck{Cards: []models.CardQuantity{
+		{CardID: "01DE001", Quantity: 1},
+		{CardID: "01FR001", Quantity: 1},
+		{CardID: "01DE001", Quantity: 1},
+	}}
+	violations := deck.Violations(models.FormatSingleton, false, false)
+	assert.Equal(t, []models.ViolationCode{models.ViolationTooManyCopies}, violationCodes(violations))
+	assert.Equal(t, "01DE001", violations[0].CardID)
+
+	deck = models.Deck{Cards: []models.CardQuantity{
+		{CardID: "01DE001", Quantity: 2},
+		{CardID: "01DE001", Quantity: 2},
+	}}
+	violations = deck.Violations(models.FormatStandard, false, false)
+	assert.Equal(t, []models.ViolationCode{models.ViolationTooManyCopies}, violationCodes(violations))
+
+	deck = models.Deck{Cards: []models.CardQuantity{
+		{CardID: "01DE001", Quantity: 2},
+		{CardID: "01DE001", Quantity: 1},
+	}}
+	assert.Empty(t, deck.Violations(models.FormatStandard, false, false))
+}
+
+func TestDeckViolationsInvalidQuantity(t *testing.T) {
+	defer useRulesCards()()
+
+	deck := models.Deck{Cards: []models.CardQuantity{
+		{CardID: "01DE001", Quantity: 0},
+		{CardID: "01FR001", Quantity: -1},
+	}}
+	violations := deck.Violations(models.FormatStandard, false, false)
+	assert.Equal(t, []models.ViolationCode{
+		models.ViolationInvalidQuantity,
+		models.ViolationInvalidQuantity,
+		models.ViolationEmptyDeck,
+	}, violationCodes(violations))
+	assert.Equal(t, "01DE001", violations[0].CardID)
+	assert.Equal(t, "01FR001", violations[1].CardID)
+
+	// A negative entry doesn't count towards the deck size
+	cards := []models.CardQuantity{{CardID: "01FR001", Quantity: -2}}
+	for i := 0; i < 14; i++ {
+		cards = append(cards, models.CardQuantity{CardID: "01DE001", Quantity: 3})
+	}
+	violations = models.Deck{Cards: cards}.Violations(models.FormatStandard, true, false)
+	assert.Equal(t, models.ViolationInvalidQuantity, violations[0].Code)
+	assert.Contains(t, violationCodes(violations), models.ViolationDeckSize)
+
+	valid, err := models.Deck{Cards: []models.CardQuantity{{CardID: "01DE001", Quantity: 0}}}.IsValid(false, false, false)
+	assert.False(t, valid)
+	assert.EqualError(t, err, "Invalid Deck: Card quantities must be at least 1")
+}
+
+func TestDeckViolationsRuneterra(t *testing.T) {
+	defer useRulesCards()()
+
+	deck := models.Deck{Cards: []models.CardQuantity{
+		{CardID: "06RU002", Quantity: 1},
+		{CardID: "01DE001", Quantity: 3},
+		{CardID: "01FR001", Quantity: 3},
+	}}
+	assert.Empty(t, deck.Violations(models.FormatStandard, false, false))
+
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "01IO001", Quantity: 3})
+	assert.Equal(t, []models.ViolationCode{models.ViolationTooManyRegions}, violationCodes(deck.Violations(models.FormatStandard, false, false)))
+}
+
+func TestDeckFormats(t *testing.T) {
+	defer useRulesCards()()
+
+	deck := models.Deck{Cards: []models.CardQuantity{{CardID: "01DE001", Quantity: 2}, {CardID: "04DE001", Quantity: 1}}}
+	assert.Empty(t, deck.Violations(models.FormatStandard, false, false))
+
+	violations := deck.Violations(models.FormatSingleton, false, false)
+	assert.Equal(t, []models.ViolationCode{models.ViolationTooManyCopies}, violationCodes(violations))
+	assert.Equal(t, "Deck can only contain, at most, 1 of any individual card", violations[0].Message)
+
+	rotation := models.FormatStandard
+	rotation.ID = "rotation"
+	rotation.Sets = []int{1, 2, 3}
+	models.RegisterDeckFormat(rotation)
+
+	format, ok := models.DeckFormatByID("Rotation")
+	assert.True(t, ok)
+	violations = deck.Violations(format, false, false)
+	assert.Equal(t, []models.ViolationCode{models.ViolationSetNotAllowed}, violationCodes(violations))
+	assert.Equal(t, "04DE001", violations[0].CardID)
+
+	format, ok = models.DeckFormatByID("")
+	assert.True(t, ok)
+	assert.Equal(t, models.FormatStandard.ID, format.ID)
+
+	_, ok = models.DeckFormatByID("unknown")
+	assert.False(t, ok)
+
+	deck.Format = "gauntlet"
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "01FR001", Quantity: 1}, models.CardQuantity{CardID: "01IO001", Quantity: 1})
+	regions, err := deck.CalculateRegions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Demacia", "Freljord", "Ionia"}, regions)
+
+	valid, err := deck.IsValid(true, false, false)
+	assert.True(t, valid)
+	assert.Nil(t, err)
+
+	deck.Format = ""
+	valid, err = deck.IsValid(true, false, false)
+	assert.False(t, valid)
+	assert.Equal(t, types.InvalidDeckErrorFromString("Deck can only contain cards from 2 regions"), err)
+}
+
+func TestDeckRulesError(t *testing.T) {
+	violations := []models.DeckViolation{{Code: models.ViolationEmptyDeck, Message: "Deck must include at least 1 card"}}
+
+	err := models.NewDeckRulesError(violations)
+	assert.Equal(t, "Invalid Deck: Deck must include at least 1 card", err.Error())
+	assert.Equal(t, violations, err.Details)
+}
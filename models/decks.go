@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/teris-io/shortid"
@@ -42,6 +43,25 @@ type DeckBadge struct {
 	Text  string `json:"text" bson:"text"`
 }
 
+const (
+	patchAffectedBadgeColor = "orange"
+	patchAffectedBadgeText  = "Affected by patch"
+)
+
+// PatchAffectedBadge marks a deck that uses cards changed in the version
+func PatchAffectedBadge(version string) DeckBadge {
+	text := patchAffectedBadgeText
+	if len(version) > 0 {
+		text += " " + version
+	}
+
+	return DeckBadge{Color: patchAffectedBadgeColor, Text: text}
+}
+
+func (b DeckBadge) IsPatchAffected() bool {
+	return b.Color == patchAffectedBadgeColor && strings.HasPrefix(b.Text, patchAffectedBadgeText)
+}
+
 type Deck struct {
 	ID             string         `json:"_id,omitempty" bson:"_id,omitempty"`
 	Cards          []CardQuantity `json:"cards" bson:"cards"`
@@ -61,6 +81,7 @@ type Deck struct {
 	FeaturedPlayer string         `json:"featuredPlayer,omitempty" bson:"featuredPlayer,omitempty"`
 	Badge          DeckBadge      `json:"deckBadge,omitempty" bson:"deckBadge,omitempty"`
 	Sandbox        bool           `json:"sandbox" bson:"sandbox"`
+	Format         string         `json:"format,omitempty" bson:"format,omitempty"`
 	Popularity     int            `json:"popularity,omitempty,truncate" bson:"popularity,omitempty,truncate"`
 }
 
@@ -78,18 +99,93 @@ func containsRegion(regions []string, region string) bool {
 	return false
 }
 
-func (d Deck) CalculateRegions() []string {
-	regions := []string{}
+// MaxDeckRegions is how many regions a Standard deck can draw its cards from
+const MaxDeckRegions = 2
 
+// CalculateRegions picks the fewest regions that every card in the deck can be played in. Multi-region cards
+// count towards whichever of their regions the rest of the deck needs. When several choices are equally small,
+// the one matching the most cards' primary regions wins. Runeterra cards don't take up a region. Decks needing more
+// regions than their format allows are invalid.
+func (d Deck) CalculateRegions() ([]string, error) {
+	maxRegions := d.DeckFormat().MaxRegions
+	if regions, ok := d.minimalRegions(maxRegions); ok {
+		return regions, nil
+	}
+
+	return nil, types.InvalidDeckErrorFromString(fmt.Sprintf("Deck can only contain cards from %d regions", maxRegions))
+}
+
+// minimalRegions finds the smallest region assignment with at most maxRegions regions, if there is one
+func (d Deck) minimalRegions(maxRegions int) ([]string, bool) {
+	var cardOptions [][]string
+	var candidates []string
 	for _, cardQuant := range d.Cards {
 		card := Cards.GetCard(cardQuant.CardID)
+		if card == nil || card.IsRuneterra() {
+			continue
+		}
+
+		options := card.RegionOptions()
+		cardOptions = append(cardOptions, options)
+		for _, region := range options {
+			if !containsRegion(candidates, region) {
+				candidates = append(candidates, region)
+			}
+		}
+	}
 
-		if !containsRegion(regions, card.Region) {
-			regions = append(regions, card.Region)
+	for size := 0; size <= maxRegions; size++ {
+		best, bestScore := []string(nil), -1
+		forEachRegionCombination(candidates, size, func(regions []string) {
+			score, covered := scoreRegions(cardOptions, regions)
+			if covered && score > bestScore {
+				best, bestScore = append([]string{}, regions...), score
+			}
+		})
+		if best != nil {
+			return best, true
 		}
 	}
 
-	return regions
+	return nil, false
+}
+
+// forEachRegionCombination calls fn with every combination of size regions, keeping the candidates' order
+func forEachRegionCombination(candidates []string, size int, fn func([]string)) {
+	var combine func(start int, chosen []string)
+	combine = func(start int, chosen []string) {
+		if len(chosen) == size {
+			fn(chosen)
+			return
+		}
+		for i := start; i < len(candidates); i++ {
+			combine(i+1, append(chosen, candidates[i]))
+		}
+	}
+	combine(0, make([]string, 0, size))
+}
+
+// scoreRegions reports whether every card can be played in one of the regions, and how many are in their primary region
+func scoreRegions(cardOptions [][]string, regions []string) (int, bool) {
+	score := 0
+	for _, options := range cardOptions {
+		if containsRegion(regions, options[0]) {
+			score++
+			continue
+		}
+
+		covered := false
+		for _, region := range options[1:] {
+			if containsRegion(regions, region) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return 0, false
+		}
+	}
+	return score, true
 }
 
 func (d Deck) AllCardsValid() (bool, error) {
@@ -120,7 +216,7 @@ func (d Deck) ChampionCount() int {
 	for _, cardQuant := range d.Cards {
 		card := Cards.GetCard(cardQuant.CardID)
 
-		if card.Supertype == "Champion" {
+		if card != nil && card.Supertype == "Champion" {
 			count += cardQuant.Quantity
 		}
 	}
@@ -128,28 +224,15 @@ func (d Deck) ChampionCount() int {
 	return count
 }
 
+// IsValid checks the deck against its format's rules, returning the first violation. When strict is false an
+// empty deck is allowed. Use Violations to get every broken rule.
 func (d Deck) IsValid(strict, publish, sandbox bool) (bool, error) {
-	if valid, cardID := d.AllCardsValid(); !valid {
-		return false, types.InvalidDeckErrorFromString(fmt.Sprintf("Card with ID %d does not exist", cardID))
-	}
-
-	cardCount := d.CardCount()
-	if publish && !sandbox && cardCount != 40 {
-		return false, types.InvalidDeckErrorFromString("Deck must include 40 cards to be published")
-	}
-
-	if strict && cardCount == 0 {
-		return false, types.InvalidDeckErrorFromString("Deck must include at least 1 card")
-	}
-
-	if d.ChampionCount() > 6 && publish {
-		return false, types.InvalidDeckErrorFromString("Deck can only contain at most 6 Champion Cards")
-	}
-
-	for _, cardQuant := range d.Cards {
-		if cardQuant.Quantity > 3 {
-			return false, types.InvalidDeckErrorFromString("Deck can only contain, at most, 3 of any individual card")
+	for _, violation := range d.Violations(d.DeckFormat(), publish, sandbox) {
+		if !strict && violation.Code == ViolationEmptyDeck {
+			continue
 		}
+
+		return false, types.InvalidDeckErrorFromString(violation.Message)
 	}
 
 	return true, nil
@@ -181,6 +264,71 @@ func (d Deck) Encode() string {
 	return code
 }
 
+// StableCode keeps the deck's current code while it still describes the deck's cards, and encodes it otherwise.
+// Codes from older encoder versions can differ from what Encode returns today, and re-saving a deck shouldn't
+// change a code that players may already have shared.
+func (d Deck) StableCode() string {
+	if len(d.DeckCode) > 0 && d.codeMatchesCards() {
+		return d.DeckCode
+	}
+	return d.Encode()
+}
+
+func (d Deck) codeMatchesCards() bool {
+	decoded, err := deck_encoder.Decode(d.DeckCode)
+	if err != nil {
+		return false
+	}
+
+	counts := make(map[string]int)
+	for _, cardQuant := range d.Cards {
+		counts[cardQuant.CardID] += cardQuant.Quantity
+	}
+	for _, cardInDeck := range decoded.Cards {
+		counts[cardInDeck.Card.String()] -= cardInDeck.Count
+	}
+
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// DeckFromCode builds an unsaved deck from a deck code, returning the codes of any cards that could not be found.
+// The draft isn't checked against its format's rules; use Violations to report them.
+func DeckFromCode(code string) (*Deck, []string, error) {
+	decoded, err := deck_encoder.Decode(code)
+	if err != nil {
+		return nil, nil, &types.InvalidDeckError{Err: err}
+	}
+
+	deck := Deck{
+		DeckCode: code,
+		Cards:    make([]CardQuantity, 0),
+	}
+	unknownCards := make([]string, 0)
+
+	for _, cardInDeck := range decoded.Cards {
+		cardCode := cardInDeck.Card.String()
+		card := Cards.GetCard(cardCode)
+
+		if card == nil {
+			unknownCards = append(unknownCards, cardCode)
+			continue
+		}
+
+		deck.Cards = append(deck.Cards, CardQuantity{CardID: card.ID, Quantity: cardInDeck.Count})
+	}
+
+	// Drafts are returned even when they break the format's rules, so every region the cards need is listed.
+	// Each card needs at most one region, so the assignment always fits in one region per card.
+	deck.Regions, _ = deck.minimalRegions(len(deck.Cards))
+
+	return &deck, unknownCards, nil
+}
+
 func InitDeckModel(d *db.Database) *DeckModel {
 	collection := d.Collection("decks")
 	m := NewDeckModel(collection)
@@ -320,6 +468,70 @@ func (m DeckModel) SearchDecks(search string) ([]*Deck, error) {
 	return data, nil
 }
 
+// SetOwnerUsername keeps the denormalised owner name on the user's decks in step with their account
+func (m DeckModel) SetOwnerUsername(ownerID, username string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"owner": ownerID}
+	update := bson.M{"$set": bson.M{"ownerUsername": username}}
+
+	res, err := m.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// FlagPatchAffectedDecks gives the badge to every published deck that uses one of the cards, returning those decks.
+// Decks with another badge keep it, but are still returned.
+func (m DeckModel) FlagPatchAffectedDecks(cardIDs []string, badge DeckBadge) ([]*Deck, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	decks := make([]*Deck, 0)
+	if len(cardIDs) == 0 {
+		return decks, nil
+	}
+
+	filter := bson.M{"published": true, "deleted": false, "cards.cardId": bson.M{"$in": cardIDs}}
+	cur, err := m.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close(ctx)
+
+	if err := cur.All(ctx, &decks); err != nil {
+		return nil, err
+	}
+
+	if len(decks) == 0 {
+		return decks, nil
+	}
+
+	// Editorial badges such as Featured are kept, so only decks without one get the patch badge
+	deckIDs := make([]string, 0, len(decks))
+	for _, deck := range decks {
+		if deck.Badge != (DeckBadge{}) && !deck.Badge.IsPatchAffected() {
+			continue
+		}
+		deck.Badge = badge
+		deckIDs = append(deckIDs, deck.ID)
+	}
+	if len(deckIDs) == 0 {
+		return decks, nil
+	}
+
+	update := bson.M{"$set": bson.M{"deckBadge": badge}}
+	if _, err := m.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": deckIDs}}, update); err != nil {
+		return nil, err
+	}
+
+	return decks, nil
+}
+
 func (m DeckModel) DeleteDeck(deckID string) (*Deck, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -347,7 +559,7 @@ func (m DeckModel) PublishDeck(deckID string) (*Deck, error) {
 	filterParams[1] = bson.M{"deleted": false}
 
 	filter := bson.M{"$and": filterParams}
-	update := bson.M{"$set": bson.M{"published": true}}
+	update := bson.M{"$set": bson.M{"published": true, "datePublished": time.Now()}}
 	after := options.After
 	options := options.FindOneAndUpdateOptions{
 		ReturnDocument: &after,
@@ -377,6 +589,29 @@ func (m DeckModel) GetPopularDecks(query SearchPopularDecksQuery) ([]Deck, error
 	return decks, nil
 }
 
+func (m DeckModel) CountPopularDecks(query SearchPopularDecksQuery) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	countCurr, err := m.collection.Aggregate(ctx, query.GenerateCountPipeline())
+	if err != nil {
+		return 0, err
+	}
+
+	var results []struct {
+		Total int `bson:"total"`
+	}
+	if err = countCurr.All(ctx, &results); err != nil {
+		return 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, nil
+	}
+
+	return results[0].Total, nil
+}
+
 func generateAddFieldsStage() bson.D {
 	msToHoursRatio := 3600 * 1000
 	cardIds := bson.M{"$map": bson.M{"input": "$cards", "as": "card", "in": "$$card.cardId"}}
@@ -392,9 +627,9 @@ func generateAddFieldsStage() bson.D {
 	return bson.D{{Key: "$addFields", Value: bson.M{"cardIds": cardIds, "popularity": popularity}}}
 }
 
-func (q SearchPopularDecksQuery) searchField() string {
-	if len(q.Search) > 0 {
-		return q.Search
+func (q SearchPopularDecksQuery) sortField() string {
+	if len(q.Sorting) > 0 {
+		return q.Sorting
 	}
 
 	return "popularity"
@@ -408,7 +643,7 @@ func (q SearchPopularDecksQuery) sortAsc() int {
 	return q.SortAsc * -1
 }
 
-func (q SearchPopularDecksQuery) GeneratePipeline() mongo.Pipeline {
+func (q SearchPopularDecksQuery) generateMatchStage() bson.D {
 	matchQuery := bson.M{"published": true, "datePublished": bson.M{"$exists": true}}
 
 	if q.FeaturedPlayer {
@@ -434,12 +669,15 @@ func (q SearchPopularDecksQuery) GeneratePipeline() mongo.Pipeline {
 		matchQuery["types"] = bson.M{"$all": q.Types}
 	}
 
-	sortField := q.searchField()
+	return bson.D{{Key: "$match", Value: matchQuery}}
+}
 
+func (q SearchPopularDecksQuery) GeneratePipeline() mongo.Pipeline {
 	addFieldsStage := generateAddFieldsStage()
-	matchStage := bson.D{{Key: "$match", Value: matchQuery}}
+	matchStage := q.generateMatchStage()
+	sortStage := bson.D{{Key: "$sort", Value: bson.M{q.sortField(): q.sortAsc()}}}
 
-	pipeline := mongo.Pipeline{addFieldsStage, matchStage}
+	pipeline := mongo.Pipeline{addFieldsStage, matchStage, sortStage}
 
 	if q.Limit > 0 {
 		if q.Page > 0 {
@@ -451,8 +689,13 @@ func (q SearchPopularDecksQuery) GeneratePipeline() mongo.Pipeline {
 		pipeline = append(pipeline, limitStage)
 	}
 
-	sortStage := bson.D{{Key: "$sort", Value: bson.M{sortField: q.sortAsc()}}}
-	pipeline = append(pipeline, sortStage)
-
 	return pipeline
 }
+
+func (q SearchPopularDecksQuery) GenerateCountPipeline() mongo.Pipeline {
+	addFieldsStage := generateAddFieldsStage()
+	matchStage := q.generateMatchStage()
+	countStage := bson.D{{Key: "$count", Value: "total"}}
+
+	return mongo.Pipeline{addFieldsStage, matchStage, countStage}
+}
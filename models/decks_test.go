@@ -29,6 +29,9 @@ func TestDeckChampionCount(t *testing.T) {
 	received := deck.ChampionCount()
 
 	assert.Equal(t, 3, received)
+
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "missing", Quantity: 1})
+	assert.Equal(t, 3, deck.ChampionCount())
 }
 
 func TestDeckCalculateRegions(t *testing.T) {
@@ -36,9 +39,81 @@ func TestDeckCalculateRegions(t *testing.T) {
 		Cards: []models.CardQuantity{{CardID: "01FR024", Quantity: 3}, {CardID: "01IO012", Quantity: 3}},
 	}
 
-	received := deck.CalculateRegions()
+	received, err := deck.CalculateRegions()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Freljord", "Ionia"}, received)
+}
+
+func TestDeckCalculateMultiRegions(t *testing.T) {
+	savedCards := models.Cards
+	defer func() { models.Cards = savedCards }()
+
+	models.Cards = models.NewCardModel(nil)
+	models.Cards.SetCards([]models.Card{
+		{ID: "01DE001", CardCode: "01DE001", Region: "Demacia", Regions: []string{"Demacia"}},
+		{ID: "01FR001", CardCode: "01FR001", Region: "Freljord", Regions: []string{"Freljord"}},
+		{ID: "04BC001", CardCode: "04BC001", Region: "Bandle City", Regions: []string{"Bandle City", "Demacia"}},
+		{ID: "04BC002", CardCode: "04BC002", Region: "Bandle City", Regions: []string{"Bandle City", "Freljord"}},
+		{ID: "01IO001", CardCode: "01IO001", Region: "Ionia", Regions: []string{"Ionia"}},
+	})
+
+	deck := models.Deck{Cards: []models.CardQuantity{{CardID: "01DE001", Quantity: 3}, {CardID: "04BC001", Quantity: 3}}}
+	regions, err := deck.CalculateRegions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Demacia"}, regions)
+
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "04BC002", Quantity: 3})
+	regions, err = deck.CalculateRegions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Demacia", "Bandle City"}, regions)
+
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "01FR001", Quantity: 3})
+	regions, err = deck.CalculateRegions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Demacia", "Freljord"}, regions)
+
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "01IO001", Quantity: 3})
+	_, err = deck.CalculateRegions()
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 10, models.Cards.GetCard("04BC001").ToEncodableCard().Faction)
+}
+
+func TestDeckCalculateRegionsRuneterra(t *testing.T) {
+	savedCards := models.Cards
+	defer func() { models.Cards = savedCards }()
+
+	models.Cards = models.NewCardModel(nil)
+	models.Cards.SetCards([]models.Card{
+		{ID: "01DE001", CardCode: "01DE001", CardSet: 1, Region: "Demacia", Regions: []string{"Demacia"}},
+		{ID: "01FR001", CardCode: "01FR001", CardSet: 1, Region: "Freljord", Regions: []string{"Freljord"}},
+		{ID: "01IO001", CardCode: "01IO001", CardSet: 1, Region: "Ionia", Regions: []string{"Ionia"}},
+		{ID: "06RU002", CardCode: "06RU002", CardSet: 6, Region: "Runeterra", Regions: []string{"Runeterra"}, Supertype: "Champion"},
+	})
+
+	deck := models.Deck{Cards: []models.CardQuantity{
+		{CardID: "06RU002", Quantity: 1},
+		{CardID: "01DE001", Quantity: 3},
+		{CardID: "01FR001", Quantity: 3},
+	}}
+	regions, err := deck.CalculateRegions()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Demacia", "Freljord"}, regions)
+
+	regions, err = models.Deck{Cards: []models.CardQuantity{{CardID: "06RU002", Quantity: 1}}}.CalculateRegions()
+	assert.Nil(t, err)
+	assert.Empty(t, regions)
+
+	deck.Cards = append(deck.Cards, models.CardQuantity{CardID: "01IO001", Quantity: 3})
+	_, err = deck.CalculateRegions()
+	assert.NotNil(t, err)
 
-	assert.Equal(t, []string{"Freljord", "Noxus"}, received)
+	// Imported drafts keep every region they need, so the import can report the broken rule
+	draft, unknownCards, err := models.DeckFromCode(deck.Encode())
+	assert.Nil(t, err)
+	assert.Empty(t, unknownCards)
+	assert.Equal(t, []string{"Demacia", "Freljord", "Ionia"}, draft.Regions)
 }
 
 func TestAllCardsValid(t *testing.T) {
@@ -94,7 +169,7 @@ func TestEncodeDeck(t *testing.T) {
 
 	code := deck.Encode()
 
-	assert.Equal(t, "CQBACAIBDAAQCAYMAAAA", code)
+	assert.Equal(t, "CEBACAIBDAAQCAYMAAAA", code)
 
 	decoded, err := deck_encoder.Decode(code)
 
@@ -102,6 +177,49 @@ func TestEncodeDeck(t *testing.T) {
 	assert.Equal(t, deck.ToEncodableDeck(), decoded)
 }
 
+func TestStableCode(t *testing.T) {
+	deck := models.Deck{
+		Cards: []models.CardQuantity{{CardID: "01FR024", Quantity: 3}, {CardID: "01IO012", Quantity: 3}},
+	}
+	// Codes saved before the encoder picked the minimum version were always written as version 4
+	oldCode := deck_encoder.Encode(deck.ToEncodableDeck(), 1, 4)
+	deck.DeckCode = oldCode
+
+	assert.NotEqual(t, oldCode, deck.Encode())
+	assert.Equal(t, oldCode, deck.StableCode())
+
+	deck.Cards[1].Quantity = 2
+	assert.Equal(t, deck.Encode(), deck.StableCode())
+
+	deck.DeckCode = ""
+	assert.Equal(t, deck.Encode(), deck.StableCode())
+}
+
+func TestDeckFromCode(t *testing.T) {
+	deck, unknownCards, err := models.DeckFromCode("CQBACAIBDAAQCAYMAAAA")
+
+	assert.Nil(t, err)
+	assert.Empty(t, unknownCards)
+	assert.Equal(t, "CQBACAIBDAAQCAYMAAAA", deck.DeckCode)
+	assert.Equal(t, []models.CardQuantity{{CardID: "01FR024", Quantity: 3}, {CardID: "01NX012", Quantity: 3}}, deck.Cards)
+	assert.Equal(t, []string{"Freljord", "Noxus"}, deck.Regions)
+
+	unknownCode := deck_encoder.Encode(deck_encoder.Deck{
+		Cards: []deck_encoder.CardInDeck{
+			{Card: deck_encoder.Card{Set: 1, Faction: 1, Number: 24}, Count: 2},
+			{Card: deck_encoder.Card{Set: 1, Faction: 0, Number: 999}, Count: 1},
+		},
+	})
+	deck, unknownCards, err = models.DeckFromCode(unknownCode)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"01DE999"}, unknownCards)
+	assert.Equal(t, []models.CardQuantity{{CardID: "01FR024", Quantity: 2}}, deck.Cards)
+
+	_, _, err = models.DeckFromCode("not a deck code")
+	assert.NotNil(t, err)
+}
+
 func saveDeck() (*models.Deck, error) {
 	newDeck := models.Deck{
 		Title:         "Some Test Deck",
@@ -175,6 +293,21 @@ func TestGetDecksByOwnerID(t *testing.T) {
 	assert.Empty(t, received)
 }
 
+func TestSetOwnerUsername(t *testing.T) {
+	deck, err := models.Decks.SaveDeck(models.Deck{Title: "Renamed Owner Deck", Owner: "rename_owner", OwnerUsername: "old_name"})
+	if err != nil {
+		panic(err)
+	}
+
+	updated, err := models.Decks.SetOwnerUsername("rename_owner", "new_name")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	received, err := models.Decks.GetDeck(deck.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "new_name", received.OwnerUsername)
+}
+
 func TestSearchDecks(t *testing.T) {
 	deck, err := saveDeck()
 	if err != nil {
@@ -237,6 +370,7 @@ func TestPublishDeck(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, true, publishedDeck.Published)
+	assert.False(t, publishedDeck.DatePublished.IsZero())
 
 	_, err = models.Decks.DeleteDeck(deck.ID)
 	if err != nil {
@@ -299,6 +433,10 @@ func TestSearchPopularDecks(t *testing.T) {
 	assert.Equal(t, savedDecks[0].ID, resp[1].ID)
 	assert.Equal(t, savedDecks[1].ID, resp[0].ID)
 
+	total, err := models.Decks.CountPopularDecks(limitQuery)
+	assert.Nil(t, err)
+	assert.Equal(t, len(savedDecks), total)
+
 	paginatedQuery := models.SearchPopularDecksQuery{Limit: 1, Page: 2}
 	resp, err = models.Decks.GetPopularDecks(paginatedQuery)
 	if err != nil {
@@ -327,6 +465,10 @@ func TestSearchPopularDecks(t *testing.T) {
 	assert.Equal(t, savedDecks[1].ID, resp[0].ID)
 	assert.Equal(t, savedDecks[2].ID, resp[1].ID)
 
+	total, err = models.Decks.CountPopularDecks(searchRegionQuery)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+
 	searchMultiRegionQuery := models.SearchPopularDecksQuery{Regions: []string{"Noxus", "Demacia"}}
 	resp, err = models.Decks.GetPopularDecks(searchMultiRegionQuery)
 	if err != nil {
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/deck_encoder"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GlobalTerm is a named value from the Data Dragon core bundle, such as a keyword, rarity or spell speed
+type GlobalTerm struct {
+	Name        string `json:"name" bson:"name"`
+	NameRef     string `json:"nameRef" bson:"nameRef"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+}
+
+type GlobalRegion struct {
+	Name             string `json:"name" bson:"name"`
+	NameRef          string `json:"nameRef" bson:"nameRef"`
+	Abbreviation     string `json:"abbreviation" bson:"abbreviation"`
+	IconAbsolutePath string `json:"iconAbsolutePath" bson:"iconAbsolutePath"`
+}
+
+type GlobalSet struct {
+	Name             string `json:"name" bson:"name"`
+	NameRef          string `json:"nameRef" bson:"nameRef"`
+	IconAbsolutePath string `json:"iconAbsolutePath" bson:"iconAbsolutePath"`
+}
+
+// GameGlobals is the contents of the core bundle's globals file for one locale
+type GameGlobals struct {
+	Locale      string         `json:"locale" bson:"_id"`
+	VocabTerms  []GlobalTerm   `json:"vocabTerms" bson:"vocabTerms"`
+	Keywords    []GlobalTerm   `json:"keywords" bson:"keywords"`
+	Regions     []GlobalRegion `json:"regions" bson:"regions"`
+	SpellSpeeds []GlobalTerm   `json:"spellSpeeds" bson:"spellSpeeds"`
+	Rarities    []GlobalTerm   `json:"rarities" bson:"rarities"`
+	Sets        []GlobalSet    `json:"sets" bson:"sets"`
+	DateUpdated time.Time      `json:"date_updated" bson:"date_updated"`
+}
+
+// RegisterFactions adds the globals' regions to the deck encoder's faction registry, updating the names of known
+// factions. Data Dragon doesn't give regions a deckcode number, so new regions are only added when ids has one
+// for their abbreviation. The abbreviations of regions that couldn't be added are returned.
+// Only DefaultLocale globals are used, so display names stay in English.
+func (g GameGlobals) RegisterFactions(ids map[string]int) []string {
+	if g.Locale != DefaultLocale {
+		return nil
+	}
+
+	var skipped []string
+	for _, region := range g.Regions {
+		faction, ok := deck_encoder.FactionByCode(region.Abbreviation)
+		if !ok {
+			id, hasID := factionID(ids, region.Abbreviation)
+			if !hasID {
+				skipped = append(skipped, region.Abbreviation)
+				continue
+			}
+			faction = deck_encoder.FactionInfo{ID: id, Code: deck_encoder.Faction(region.Abbreviation)}
+		}
+
+		// Stored cards and decks may still use the old names, so they are kept as aliases
+		oldNames := []string{faction.Ref, faction.Name}
+		faction.Ref = region.NameRef
+		faction.Name = region.Name
+		for _, name := range oldNames {
+			if len(name) > 0 && !faction.HasName(name) {
+				faction.Aliases = append(faction.Aliases, name)
+			}
+		}
+
+		deck_encoder.RegisterFaction(faction)
+	}
+
+	return skipped
+}
+
+// factionID finds the configured id for an abbreviation. Config keys may have been lower cased.
+func factionID(ids map[string]int, abbreviation string) (int, bool) {
+	for code, id := range ids {
+		if strings.EqualFold(code, abbreviation) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+type GlobalsModel struct {
+	collection *mongo.Collection
+}
+
+func InitGlobalsModel(d *db.Database) *GlobalsModel {
+	return NewGlobalsModel(d.Collection("globals"))
+}
+
+func NewGlobalsModel(c *mongo.Collection) *GlobalsModel {
+	return &GlobalsModel{
+		collection: c,
+	}
+}
+
+// SaveGlobals replaces the stored globals for the locale
+func (m *GlobalsModel) SaveGlobals(globals GameGlobals) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	globals.DateUpdated = time.Now()
+	filter := bson.M{"_id": globals.Locale}
+
+	_, err := m.collection.ReplaceOne(ctx, filter, globals, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *GlobalsModel) GetGlobals(locale string) (*GameGlobals, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var globals GameGlobals
+	result := m.collection.FindOne(ctx, bson.M{"_id": locale})
+	if err := result.Decode(&globals); err != nil {
+		return nil, err
+	}
+
+	return &globals, nil
+}
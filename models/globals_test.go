@@ -0,0 +1,51 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/deck_encoder"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+func TestRegisterFactions(t *testing.T) {
+	globals := models.GameGlobals{
+		Locale: models.DefaultLocale,
+		Regions: []models.GlobalRegion{
+			{Name: "Demacia", NameRef: "Demacia", Abbreviation: "DE"},
+			{Name: "Shadowlands", NameRef: "Shadowlands", Abbreviation: "SL"},
+			{Name: "Unnumbered", NameRef: "Unnumbered", Abbreviation: "UN"},
+		},
+	}
+
+	skipped := globals.RegisterFactions(map[string]int{"sl": 21})
+	assert.Equal(t, []string{"UN"}, skipped)
+
+	faction, ok := deck_encoder.FactionByRegion("Shadowlands")
+	assert.True(t, ok)
+	assert.Equal(t, 21, faction.ID)
+	assert.Equal(t, deck_encoder.Faction("SL"), faction.Code)
+
+	faction, _ = deck_encoder.FactionByCode("DE")
+	assert.Equal(t, 0, faction.ID)
+
+	targon, _ := deck_encoder.FactionByCode("MT")
+	defer deck_encoder.RegisterFaction(targon)
+
+	renamed := models.GameGlobals{
+		Locale:  models.DefaultLocale,
+		Regions: []models.GlobalRegion{{Name: "Mount Targon", NameRef: "MountTargon", Abbreviation: "MT"}},
+	}
+	assert.Empty(t, renamed.RegisterFactions(nil))
+
+	faction, _ = deck_encoder.FactionByCode("MT")
+	assert.Equal(t, "Mount Targon", faction.Name)
+	assert.Equal(t, 9, deck_encoder.FactionNumberFromName("Targon"))
+	assert.Equal(t, 9, deck_encoder.FactionNumberFromName("MountTargon"))
+
+	card := models.Card{ID: "05SL001", CardCode: "05SL001", Region: "Shadowlands", RegionRef: "Shadowlands", CardSet: 5}
+	assert.Equal(t, 21, card.ToEncodableCard().Faction)
+
+	globals.Locale = "fr_fr"
+	assert.Nil(t, globals.RegisterFactions(nil))
+}
@@ -52,6 +52,11 @@ func init() {
 	database.DropCollection("decks")
 	database.DropCollection("archetypes")
 	database.DropCollection("users")
+	database.DropCollection("sessions")
+	database.DropCollection("user_tokens")
+	database.DropCollection("globals")
+	database.DropCollection("card_revisions")
+	database.DropCollection("affected_decks")
 	models.InitModels(database)
 	saveDecks()
 	saveArchetypes()
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is the named form of User.Access. Roles are ordered, so a higher role has every permission of the roles below it.
+type Role int
+
+const (
+	RoleUser      Role = 0
+	RoleEditor    Role = 1
+	RoleModerator Role = 2
+	RoleAdmin     Role = 3
+)
+
+var roleNames = map[Role]string{
+	RoleUser:      "user",
+	RoleEditor:    "editor",
+	RoleModerator: "moderator",
+	RoleAdmin:     "admin",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("role(%d)", int(r))
+}
+
+func (r Role) IsValid() bool {
+	_, ok := roleNames[r]
+	return ok
+}
+
+func ParseRole(name string) (Role, error) {
+	for role, roleName := range roleNames {
+		if strings.EqualFold(roleName, name) {
+			return role, nil
+		}
+	}
+
+	return RoleUser, fmt.Errorf("Unknown role %s", name)
+}
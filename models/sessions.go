@@ -0,0 +1,318 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const SessionLifetime = 30 * 24 * time.Hour
+
+// previousHashLimit is how many rotated out refresh tokens are remembered to detect reuse
+const previousHashLimit = 20
+
+var ErrInvalidRefreshToken = types.Unauthorized("Invalid refresh token")
+
+// Session is a single login. Its refresh token is stored hashed and changes every time it is used.
+type Session struct {
+	ID               primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID           string             `json:"user_id" bson:"user_id"`
+	RefreshTokenHash string             `json:"-" bson:"refresh_token_hash"`
+	PreviousHashes   []string           `json:"-" bson:"previous_token_hashes,omitempty"`
+	UserAgent        string             `json:"user_agent" bson:"user_agent"`
+	IP               string             `json:"ip" bson:"ip"`
+	Revoked          bool               `json:"revoked" bson:"revoked"`
+	DateCreated      time.Time          `json:"date_created" bson:"date_created"`
+	DateUsed         time.Time          `json:"date_used" bson:"date_used"`
+	DateExpires      time.Time          `json:"date_expires" bson:"date_expires"`
+	DateRevoked      time.Time          `json:"date_revoked,omitempty" bson:"date_revoked,omitempty"`
+}
+
+func (s Session) SessionID() string {
+	return s.ID.Hex()
+}
+
+func (s Session) IsActive() bool {
+	return !s.Revoked && time.Now().Before(s.DateExpires)
+}
+
+type SessionModel struct {
+	collection *mongo.Collection
+}
+
+func InitSessionModel(d *db.Database) *SessionModel {
+	collection := d.Collection("sessions")
+	indices := make([]mongo.IndexModel, 2)
+	indices[0] = mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}},
+	}
+	indices[1] = mongo.IndexModel{
+		Keys:    bson.D{{Key: "date_expires", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	_, err := collection.Indexes().CreateMany(
+		context.Background(),
+		indices,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewSessionModel(collection)
+}
+
+func NewSessionModel(c *mongo.Collection) *SessionModel {
+	return &SessionModel{
+		collection: c,
+	}
+}
+
+func generateSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// splitRefreshToken separates a refresh token into its session ID and secret
+func splitRefreshToken(refreshToken string) (primitive.ObjectID, string, error) {
+	parts := strings.SplitN(refreshToken, ".", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return primitive.NilObjectID, "", ErrInvalidRefreshToken
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return primitive.NilObjectID, "", ErrInvalidRefreshToken
+	}
+
+	return sessionID, parts[1], nil
+}
+
+// CreateSession starts a new session for the user and returns it along with its first refresh token
+func (m *SessionModel) CreateSession(userID, userAgent, ip string) (*Session, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := Session{
+		ID:               primitive.NewObjectID(),
+		UserID:           userID,
+		RefreshTokenHash: hashSecret(secret),
+		UserAgent:        userAgent,
+		IP:               ip,
+		DateCreated:      now,
+		DateUsed:         now,
+		DateExpires:      now.Add(SessionLifetime),
+	}
+
+	_, err = m.collection.InsertOne(ctx, session)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, session.SessionID() + "." + secret, nil
+}
+
+// RotateSession exchanges a refresh token for a new one. Presenting a refresh token that has already been
+// rotated means it has leaked, so the whole session is revoked.
+func (m *SessionModel) RotateSession(refreshToken, userAgent, ip string) (*Session, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sessionID, secret, err := splitRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	newSecret, err := generateSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	secretHash := hashSecret(secret)
+	filter := bson.M{
+		"_id":                sessionID,
+		"refresh_token_hash": secretHash,
+		"revoked":            false,
+		"date_expires":       bson.M{"$gt": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"refresh_token_hash": hashSecret(newSecret),
+			"user_agent":         userAgent,
+			"ip":                 ip,
+			"date_used":          now,
+			"date_expires":       now.Add(SessionLifetime),
+		},
+		"$push": bson.M{"previous_token_hashes": bson.M{
+			"$each":  []string{secretHash},
+			"$slice": -previousHashLimit,
+		}},
+	}
+
+	var session Session
+	after := options.After
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	res := m.collection.FindOneAndUpdate(ctx, filter, update, &options)
+	err = res.Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		// A token that was already rotated out means it was copied, so the session can't be trusted any more.
+		// Anything else is just a bad token, which mustn't let someone who guessed the session ID log it out.
+		m.revoke(ctx, bson.M{"_id": sessionID, "previous_token_hashes": secretHash})
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, session.SessionID() + "." + newSecret, nil
+}
+
+func (m *SessionModel) GetSession(id string) (*Session, error) {
+	sessionID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	result := m.collection.FindOne(context.Background(), bson.M{"_id": sessionID})
+	err = result.Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (m *SessionModel) IsSessionActive(id string) (bool, error) {
+	session, err := m.GetSession(id)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return session.IsActive(), nil
+}
+
+func (m *SessionModel) GetActiveSessions(userID string) ([]*Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var sessions []*Session
+	filter := bson.M{"user_id": userID, "revoked": false, "date_expires": bson.M{"$gt": time.Now()}}
+	sort := options.Find().SetSort(bson.M{"date_used": -1})
+
+	cur, err := m.collection.Find(ctx, filter, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close(ctx)
+
+	if err := cur.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	if sessions == nil {
+		sessions = make([]*Session, 0)
+	}
+
+	return sessions, nil
+}
+
+func (m *SessionModel) revoke(ctx context.Context, filter bson.M) (int64, error) {
+	filter["revoked"] = false
+	update := bson.M{"$set": bson.M{"revoked": true, "date_revoked": time.Now()}}
+
+	res, err := m.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// RevokeSession revokes one of the user's sessions, returning mongo.ErrNoDocuments if the user has no such active session
+func (m *SessionModel) RevokeSession(userID, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sessionID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := m.revoke(ctx, bson.M{"_id": sessionID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RevokeRefreshToken revokes the session a refresh token belongs to, for when there is no valid access token to identify it
+func (m *SessionModel) RevokeRefreshToken(refreshToken string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sessionID, secret, err := splitRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.revoke(ctx, bson.M{"_id": sessionID, "refresh_token_hash": hashSecret(secret)})
+	return err
+}
+
+// RevokeOtherSessions revokes every session the user has except the one given, e.g. after a password change
+func (m *SessionModel) RevokeOtherSessions(userID, keepID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sessionID, err := primitive.ObjectIDFromHex(keepID)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.revoke(ctx, bson.M{"user_id": userID, "_id": bson.M{"$ne": sessionID}})
+}
+
+func (m *SessionModel) RevokeAllSessions(userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return m.revoke(ctx, bson.M{"user_id": userID})
+}
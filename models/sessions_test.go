@@ -0,0 +1,135 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+func TestCreateSession(t *testing.T) {
+	session, refreshToken, err := models.Sessions.CreateSession("session_user", "test agent", "127.0.0.1")
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, refreshToken)
+	assert.NotContains(t, session.RefreshTokenHash, refreshToken)
+	assert.True(t, session.IsActive())
+
+	active, err := models.Sessions.IsSessionActive(session.SessionID())
+	assert.Nil(t, err)
+	assert.True(t, active)
+
+	active, err = models.Sessions.IsSessionActive("not a session")
+	assert.Nil(t, err)
+	assert.False(t, active)
+}
+
+func TestRotateSession(t *testing.T) {
+	session, refreshToken, err := models.Sessions.CreateSession("rotate_user", "test agent", "127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+
+	rotated, newRefreshToken, err := models.Sessions.RotateSession(refreshToken, "test agent", "127.0.0.1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, session.ID, rotated.ID)
+	assert.NotEqual(t, refreshToken, newRefreshToken)
+
+	_, _, err = models.Sessions.RotateSession(refreshToken, "test agent", "127.0.0.1")
+	assert.Equal(t, models.ErrInvalidRefreshToken, err)
+
+	active, err := models.Sessions.IsSessionActive(session.SessionID())
+	assert.Nil(t, err)
+	assert.False(t, active)
+
+	_, _, err = models.Sessions.RotateSession(newRefreshToken, "test agent", "127.0.0.1")
+	assert.Equal(t, models.ErrInvalidRefreshToken, err)
+}
+
+func TestRotateSessionBadToken(t *testing.T) {
+	session, refreshToken, err := models.Sessions.CreateSession("guessed_user", "test agent", "127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+
+	_, _, err = models.Sessions.RotateSession(session.SessionID()+".garbage", "test agent", "127.0.0.1")
+	assert.Equal(t, models.ErrInvalidRefreshToken, err)
+
+	active, err := models.Sessions.IsSessionActive(session.SessionID())
+	assert.Nil(t, err)
+	assert.True(t, active)
+
+	_, _, err = models.Sessions.RotateSession(refreshToken, "test agent", "127.0.0.1")
+	assert.Nil(t, err)
+}
+
+func TestRevokeSession(t *testing.T) {
+	session, _, err := models.Sessions.CreateSession("revoke_user", "test agent", "127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+
+	err = models.Sessions.RevokeSession("another_user", session.SessionID())
+	assert.NotNil(t, err)
+
+	err = models.Sessions.RevokeSession("revoke_user", session.SessionID())
+	assert.Nil(t, err)
+
+	active, err := models.Sessions.IsSessionActive(session.SessionID())
+	assert.Nil(t, err)
+	assert.False(t, active)
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	session, refreshToken, err := models.Sessions.CreateSession("refresh_revoke_user", "test agent", "127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+
+	err = models.Sessions.RevokeRefreshToken(refreshToken)
+	assert.Nil(t, err)
+
+	active, err := models.Sessions.IsSessionActive(session.SessionID())
+	assert.Nil(t, err)
+	assert.False(t, active)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		if _, _, err := models.Sessions.CreateSession("all_sessions_user", "test agent", "127.0.0.1"); err != nil {
+			panic(err)
+		}
+	}
+
+	sessions, err := models.Sessions.GetActiveSessions("all_sessions_user")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(sessions))
+
+	revoked, err := models.Sessions.RevokeAllSessions("all_sessions_user")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), revoked)
+
+	sessions, err = models.Sessions.GetActiveSessions("all_sessions_user")
+	assert.Nil(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	current, _, err := models.Sessions.CreateSession("other_sessions_user", "test agent", "127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+	if _, _, err := models.Sessions.CreateSession("other_sessions_user", "test agent", "127.0.0.1"); err != nil {
+		panic(err)
+	}
+
+	revoked, err := models.Sessions.RevokeOtherSessions("other_sessions_user", current.SessionID())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	sessions, err := models.Sessions.GetActiveSessions("other_sessions_user")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, current.ID, sessions[0].ID)
+}
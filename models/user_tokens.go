@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TokenPurpose string
+
+const (
+	PurposePasswordReset     TokenPurpose = "password_reset"
+	PurposeEmailVerification TokenPurpose = "email_verification"
+)
+
+// UserToken records a single-use token emailed to a user, such as a password reset link
+type UserToken struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID      string             `json:"user_id" bson:"user_id"`
+	Purpose     TokenPurpose       `json:"purpose" bson:"purpose"`
+	Email       string             `json:"email" bson:"email"`
+	Used        bool               `json:"used" bson:"used"`
+	DateCreated time.Time          `json:"date_created" bson:"date_created"`
+	DateExpires time.Time          `json:"date_expires" bson:"date_expires"`
+	DateUsed    time.Time          `json:"date_used,omitempty" bson:"date_used,omitempty"`
+}
+
+func (t UserToken) TokenID() string {
+	return t.ID.Hex()
+}
+
+type UserTokenModel struct {
+	collection *mongo.Collection
+}
+
+func InitUserTokenModel(d *db.Database) *UserTokenModel {
+	collection := d.Collection("user_tokens")
+	indices := make([]mongo.IndexModel, 2)
+	indices[0] = mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+	}
+	indices[1] = mongo.IndexModel{
+		Keys:    bson.D{{Key: "date_expires", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	_, err := collection.Indexes().CreateMany(
+		context.Background(),
+		indices,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewUserTokenModel(collection)
+}
+
+func NewUserTokenModel(c *mongo.Collection) *UserTokenModel {
+	return &UserTokenModel{
+		collection: c,
+	}
+}
+
+// CreateToken issues a new token and invalidates any earlier unused tokens the user has for the same purpose
+func (m *UserTokenModel) CreateToken(userID string, purpose TokenPurpose, email string, lifetime time.Duration) (*UserToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := m.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used": false},
+		bson.M{"$set": bson.M{"used": true, "date_used": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token := UserToken{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Purpose:     purpose,
+		Email:       email,
+		DateCreated: now,
+		DateExpires: now.Add(lifetime),
+	}
+
+	_, err = m.collection.InsertOne(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ConsumeToken marks the token as used, returning mongo.ErrNoDocuments if it does not exist, has expired or was already used
+func (m *UserTokenModel) ConsumeToken(id string, purpose TokenPurpose) (*UserToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tokenID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":          tokenID,
+		"purpose":      purpose,
+		"used":         false,
+		"date_expires": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used": true, "date_used": now}}
+
+	var token UserToken
+	after := options.After
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	res := m.collection.FindOneAndUpdate(ctx, filter, update, &options)
+	err = res.Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
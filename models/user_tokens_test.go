@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestConsumeToken(t *testing.T) {
+	token, err := models.UserTokens.CreateToken("token_user", models.PurposePasswordReset, "token@test.com", time.Hour)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = models.UserTokens.ConsumeToken(token.TokenID(), models.PurposeEmailVerification)
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+
+	consumed, err := models.UserTokens.ConsumeToken(token.TokenID(), models.PurposePasswordReset)
+	assert.Nil(t, err)
+	assert.Equal(t, "token_user", consumed.UserID)
+	assert.Equal(t, "token@test.com", consumed.Email)
+	assert.True(t, consumed.Used)
+
+	_, err = models.UserTokens.ConsumeToken(token.TokenID(), models.PurposePasswordReset)
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+
+	_, err = models.UserTokens.ConsumeToken("not a token", models.PurposePasswordReset)
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+}
+
+func TestConsumeExpiredToken(t *testing.T) {
+	token, err := models.UserTokens.CreateToken("expired_user", models.PurposePasswordReset, "expired@test.com", -time.Minute)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = models.UserTokens.ConsumeToken(token.TokenID(), models.PurposePasswordReset)
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+}
+
+func TestCreateTokenInvalidatesPrevious(t *testing.T) {
+	first, err := models.UserTokens.CreateToken("repeat_user", models.PurposeEmailVerification, "repeat@test.com", time.Hour)
+	if err != nil {
+		panic(err)
+	}
+	second, err := models.UserTokens.CreateToken("repeat_user", models.PurposeEmailVerification, "repeat@test.com", time.Hour)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = models.UserTokens.ConsumeToken(first.TokenID(), models.PurposeEmailVerification)
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+
+	_, err = models.UserTokens.ConsumeToken(second.TokenID(), models.PurposeEmailVerification)
+	assert.Nil(t, err)
+}
@@ -2,12 +2,13 @@ package models
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
-	"github.com/labstack/echo"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,6 +16,26 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUsernameTaken      = types.Conflict("An account with that username already exists")
+	ErrUsernameReserved   = types.BadRequest("That username is reserved")
+	ErrEmailTaken         = types.Conflict("An account with that email address already exists")
+	ErrInvalidCredentials = types.Unauthorized("Invalid email or password")
+)
+
+// reservedUsernames are the paths of /users routes that would shadow the profile of a user with the same name
+var reservedUsernames = []string{"auth", "login", "logout", "me", "password", "refresh", "search", "sessions", "validate", "verify"}
+
+// IsUsernameReserved reports whether the name can't be used, since it is taken by another /users route
+func IsUsernameReserved(username string) bool {
+	for _, reserved := range reservedUsernames {
+		if strings.EqualFold(reserved, username) {
+			return true
+		}
+	}
+	return false
+}
+
 type SocialLinks struct {
 	Instagram string `json:"instagram,omitempty" bson:"instagram,omitempty"`
 	Facebook  string `json:"facebook,omitempty" bson:"facebook,omitempty"`
@@ -24,20 +45,58 @@ type SocialLinks struct {
 }
 
 type User struct {
-	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Access      int                `json:"access" bson:"access"`
-	Username    string             `json:"username" bson:"username"`
-	Email       string             `json:"email" bson:"email"`
-	Password    string             `json:"password" bson:"password"`
-	DateCreated time.Time          `json:"date_created" bson:"date_created"`
-	DateUpdated time.Time          `json:"date_updated,omitempty" bson:"date_updated,omitempty"`
-	Socials     SocialLinks        `json:"socials,omitempty" bson:"socials,omitempty"`
+	ID            primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Access        int                `json:"access" bson:"access"`
+	Username      string             `json:"username" bson:"username"`
+	Email         string             `json:"email" bson:"email"`
+	EmailVerified bool               `json:"email_verified" bson:"email_verified"`
+	Password      string             `json:"-" bson:"password"`
+	DateCreated   time.Time          `json:"date_created" bson:"date_created"`
+	DateUpdated   time.Time          `json:"date_updated,omitempty" bson:"date_updated,omitempty"`
+	Socials       SocialLinks        `json:"socials,omitempty" bson:"socials,omitempty"`
+}
+
+// PublicUser is the projection of a User that is safe to put in responses and auth tokens
+type PublicUser struct {
+	ID       primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Username string             `json:"username" bson:"username"`
+	Access   int                `json:"access" bson:"access"`
+	Socials  SocialLinks        `json:"socials,omitempty" bson:"socials,omitempty"`
 }
 
 func (u User) UserID() string {
 	return u.ID.Hex()
 }
 
+func (u User) Role() Role {
+	return Role(u.Access)
+}
+
+func (u User) HasRole(role Role) bool {
+	return u.Role() >= role
+}
+
+func (u User) Public() PublicUser {
+	return PublicUser{
+		ID:       u.ID,
+		Username: u.Username,
+		Access:   u.Access,
+		Socials:  u.Socials,
+	}
+}
+
+func (u PublicUser) UserID() string {
+	return u.ID.Hex()
+}
+
+func (u PublicUser) Role() Role {
+	return Role(u.Access)
+}
+
+func (u PublicUser) HasRole(role Role) bool {
+	return u.Role() >= role
+}
+
 type UserModel struct {
 	collection *mongo.Collection
 }
@@ -75,26 +134,33 @@ func NewUserModel(c *mongo.Collection) *UserModel {
 
 func (u *UserModel) Login(email string, password string) (*User, error) {
 	user, err := u.GetUserByEmail(email)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	if !CheckPasswordHash(password, user.Password) {
-		return nil, echo.ErrUnauthorized
+		return nil, ErrInvalidCredentials
 	}
 
 	return user, nil
 }
 
 func (u *UserModel) Register(username, email, password string) (*User, error) {
+	if IsUsernameReserved(username) {
+		return nil, ErrUsernameReserved
+	}
+
 	emailUser, _ := u.GetUserByEmail(email)
 	if emailUser != nil {
-		return nil, errors.New("An account with that email address already exists")
+		return nil, ErrEmailTaken
 	}
 
 	usernameUser, _ := u.GetUserByUsername(username)
 	if usernameUser != nil {
-		return nil, errors.New("An account with that username already exists")
+		return nil, ErrUsernameTaken
 	}
 
 	hash, err := HashPassword(password)
@@ -106,7 +172,7 @@ func (u *UserModel) Register(username, email, password string) (*User, error) {
 		Username:    username,
 		Email:       email,
 		Password:    hash,
-		Access:      0,
+		Access:      int(RoleUser),
 		DateCreated: time.Now(),
 		DateUpdated: time.Now(),
 	}
@@ -226,6 +292,107 @@ func (u *UserModel) UpdateUser(user *User) (*User, error) {
 	return &updatedUser, nil
 }
 
+func (u *UserModel) setUserFields(id string, fields bson.M) (*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedUser User
+	after := options.After
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	fields["date_updated"] = time.Now()
+	res := u.collection.FindOneAndUpdate(ctx, bson.M{"_id": userID}, bson.M{"$set": fields}, &options)
+	err = res.Decode(&updatedUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedUser, nil
+}
+
+func (u *UserModel) SetPassword(id, password string) (*User, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.setUserFields(id, bson.M{"password": hash})
+}
+
+// VerifyEmail marks the user's email as verified, as long as it hasn't changed since the verification was requested
+func (u *UserModel) VerifyEmail(id, email string) (*User, error) {
+	user, err := u.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(user.Email, email) {
+		return nil, types.BadRequest("The email address has changed since verification was requested")
+	}
+
+	return u.setUserFields(id, bson.M{"email_verified": true})
+}
+
+// CheckUsernameAvailable returns ErrUsernameTaken if an account other than the user's already uses the name,
+// or ErrUsernameReserved if no account can use it
+func (u *UserModel) CheckUsernameAvailable(id, username string) error {
+	if IsUsernameReserved(username) {
+		return ErrUsernameReserved
+	}
+
+	existing, _ := u.GetUserByUsername(regexp.QuoteMeta(username))
+	if existing != nil && existing.UserID() != id {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// SetUsername renames the user, returning ErrUsernameTaken if another account already uses the name or
+// ErrUsernameReserved if no account can use it
+func (u *UserModel) SetUsername(id, username string) (*User, error) {
+	if err := u.CheckUsernameAvailable(id, username); err != nil {
+		return nil, err
+	}
+
+	user, err := u.setUserFields(id, bson.M{"username": username})
+	if isDuplicateKeyError(err) {
+		return nil, ErrUsernameTaken
+	}
+
+	return user, err
+}
+
+func (u *UserModel) SetSocials(id string, socials SocialLinks) (*User, error) {
+	return u.setUserFields(id, bson.M{"socials": socials})
+}
+
+func (u *UserModel) SetUserRole(id string, role Role) (*User, error) {
+	return u.setUserFields(id, bson.M{"access": int(role)})
+}
+
+// isDuplicateKeyError reports whether a write was rejected by a unique index
+func isDuplicateKeyError(err error) bool {
+	switch e := err.(type) {
+	case mongo.WriteException:
+		for _, writeError := range e.WriteErrors {
+			if writeError.Code == 11000 {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return e.Code == 11000
+	}
+
+	return false
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 10)
 	return string(bytes), err
@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -38,6 +39,60 @@ func TestRegister(t *testing.T) {
 	failUser, err := models.Users.Register(username, email, password)
 
 	assert.Nil(t, failUser)
+	assert.Equal(t, models.ErrEmailTaken, err)
+
+	failUser, err = models.Users.Register("Search", "search@test.com", password)
+
+	assert.Nil(t, failUser)
+	assert.Equal(t, models.ErrUsernameReserved, err)
+}
+
+func TestIsUsernameReserved(t *testing.T) {
+	assert.True(t, models.IsUsernameReserved("me"))
+	assert.True(t, models.IsUsernameReserved("Sessions"))
+	assert.False(t, models.IsUsernameReserved("test_user"))
+}
+
+func TestHasRole(t *testing.T) {
+	user := models.User{Access: int(models.RoleUser)}
+	assert.True(t, user.HasRole(models.RoleUser))
+	assert.False(t, user.HasRole(models.RoleEditor))
+
+	admin := models.User{Access: int(models.RoleAdmin)}
+	assert.True(t, admin.HasRole(models.RoleEditor))
+	assert.True(t, admin.HasRole(models.RoleAdmin))
+}
+
+func TestPublicUser(t *testing.T) {
+	user := models.User{
+		Username: "public_user",
+		Email:    "public@test.com",
+		Password: "hash",
+		Access:   int(models.RoleEditor),
+		Socials:  models.SocialLinks{Twitter: "@public_user"},
+	}
+
+	public := user.Public()
+	assert.Equal(t, user.Username, public.Username)
+	assert.Equal(t, user.Socials, public.Socials)
+	assert.Equal(t, models.RoleEditor, public.Role())
+
+	encoded, err := json.Marshal(public)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(encoded), user.Email)
+
+	encoded, err = json.Marshal(user)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(encoded), user.Password)
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := models.ParseRole("Moderator")
+	assert.Nil(t, err)
+	assert.Equal(t, models.RoleModerator, role)
+	assert.Equal(t, "moderator", role.String())
+
+	_, err = models.ParseRole("superuser")
 	assert.NotNil(t, err)
 }
 
@@ -56,7 +111,12 @@ func TestLogin(t *testing.T) {
 	failUser, err := models.Users.Login(email, wrongPassword)
 
 	assert.Nil(t, failUser)
-	assert.NotNil(t, err)
+	assert.Equal(t, models.ErrInvalidCredentials, err)
+
+	failUser, err = models.Users.Login("nobody@test.com", password)
+
+	assert.Nil(t, failUser)
+	assert.Equal(t, models.ErrInvalidCredentials, err)
 }
 
 func TestGetUserById(t *testing.T) {
@@ -116,6 +176,51 @@ func TestSearch(t *testing.T) {
 	assert.True(t, strings.Contains(usersByEmail[0].Username, username))
 }
 
+func TestSetUserRole(t *testing.T) {
+	received, err := models.Users.SetUserRole(savedUser.UserID(), models.RoleEditor)
+
+	assert.Nil(t, err)
+	assert.Equal(t, models.RoleEditor, received.Role())
+
+	received, err = models.Users.SetUserRole(savedUser.UserID(), models.RoleUser)
+
+	assert.Nil(t, err)
+	assert.Equal(t, models.RoleUser, received.Role())
+}
+
+func TestSetUsername(t *testing.T) {
+	user, err := models.Users.Register("rename_user", "rename@test.com", "password")
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = models.Users.SetUsername(user.UserID(), strings.ToUpper(savedUser.Username))
+	assert.Equal(t, models.ErrUsernameTaken, err)
+
+	assert.Equal(t, models.ErrUsernameTaken, models.Users.CheckUsernameAvailable(user.UserID(), savedUser.Username))
+	assert.Nil(t, models.Users.CheckUsernameAvailable(user.UserID(), "rename_user"))
+	assert.Nil(t, models.Users.CheckUsernameAvailable(user.UserID(), "unused_name"))
+	assert.Equal(t, models.ErrUsernameReserved, models.Users.CheckUsernameAvailable(user.UserID(), "me"))
+
+	received, err := models.Users.SetUsername(user.UserID(), "renamed.user")
+	assert.Nil(t, err)
+	assert.Equal(t, "renamed.user", received.Username)
+
+	received, err = models.Users.SetUsername(user.UserID(), "Renamed.User")
+	assert.Nil(t, err)
+	assert.Equal(t, "Renamed.User", received.Username)
+}
+
+func TestSetSocials(t *testing.T) {
+	socials := models.SocialLinks{Twitter: "socials_user", Twitch: "socials_user"}
+
+	received, err := models.Users.SetSocials(savedUser.UserID(), socials)
+
+	assert.Nil(t, err)
+	assert.Equal(t, socials, received.Socials)
+	assert.Equal(t, savedUser.Username, received.Username)
+}
+
 func TestUpdateUser(t *testing.T) {
 	socials := models.SocialLinks{
 		Instagram: "@someuser",
@@ -3,10 +3,13 @@ package types
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
+// InvalidDeckError is a deck that breaks the deckbuilding rules. Details, when set, lists every broken rule.
 type InvalidDeckError struct {
-	Err error
+	Err     error
+	Details interface{}
 }
 
 func (r *InvalidDeckError) Error() string {
@@ -18,3 +21,99 @@ func InvalidDeckErrorFromString(s string) *InvalidDeckError {
 		Err: errors.New(s),
 	}
 }
+
+// ErrorCode is the machine readable code sent with every error response
+type ErrorCode string
+
+const (
+	CodeBadRequest       ErrorCode = "bad_request"
+	CodeValidation       ErrorCode = "validation_failed"
+	CodeInvalidDeck      ErrorCode = "invalid_deck"
+	CodeUnauthorized     ErrorCode = "unauthorized"
+	CodeForbidden        ErrorCode = "forbidden"
+	CodeNotFound         ErrorCode = "not_found"
+	CodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	CodeConflict         ErrorCode = "conflict"
+	CodeTooLarge         ErrorCode = "request_too_large"
+	CodeInternal         ErrorCode = "internal_error"
+)
+
+// APIError is an error that knows the status and code it should be reported with
+type APIError struct {
+	Status  int         `json:"-"`
+	Code    ErrorCode   `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// FieldError describes one field that failed validation
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+func NewAPIError(status int, code ErrorCode, message string) *APIError {
+	return &APIError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func BadRequest(message string) *APIError {
+	return NewAPIError(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+func Validation(message string, fields []FieldError) *APIError {
+	err := NewAPIError(http.StatusBadRequest, CodeValidation, message)
+	err.Details = fields
+	return err
+}
+
+func Unauthorized(message string) *APIError {
+	return NewAPIError(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *APIError {
+	return NewAPIError(http.StatusForbidden, CodeForbidden, message)
+}
+
+func NotFound(message string) *APIError {
+	return NewAPIError(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *APIError {
+	return NewAPIError(http.StatusConflict, CodeConflict, message)
+}
+
+var ErrNotFound = NotFound("Not found")
+
+// CodeForStatus picks the error code for errors that only come with an HTTP status
+func CodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	}
+
+	if status < http.StatusInternalServerError {
+		return CodeBadRequest
+	}
+	return CodeInternal
+}
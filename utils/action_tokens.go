@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/dgrijalva/jwt-go"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+// ActionClaims are carried by the signed tokens emailed to users. The token's ID points at its models.UserToken record,
+// which is what makes it single use.
+type ActionClaims struct {
+	*jwt.StandardClaims
+	Purpose models.TokenPurpose `json:"purpose"`
+}
+
+func CreateActionToken(token models.UserToken) (string, error) {
+	claims := &ActionClaims{
+		&jwt.StandardClaims{
+			Id:        token.TokenID(),
+			Subject:   token.UserID,
+			ExpiresAt: token.DateExpires.Unix(),
+		},
+		token.Purpose,
+	}
+
+	return keys.Sign(claims)
+}
+
+// DecodeActionToken checks the token's signature, expiry and purpose and returns the ID of its stored record
+func DecodeActionToken(token string, purpose models.TokenPurpose) (string, error) {
+	parsed, err := keys.Parse(token, &ActionClaims{})
+	if err != nil {
+		return "", err
+	}
+
+	claims := parsed.Claims.(*ActionClaims)
+	if claims.Purpose != purpose || len(claims.Id) == 0 {
+		return "", errors.New("Token was not issued for this action")
+	}
+
+	return claims.Id, nil
+}
@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+)
+
+var ErrSyncRunning = types.Conflict("A card sync is already running")
+
+// DefaultCardSync is the sync shared by the scheduled job and the admin endpoints, so they can't overlap
+var DefaultCardSync *CardSync
+
+// InitCardSync sets up DefaultCardSync and registers any regions from the last saved globals
+func InitCardSync() {
+	DefaultCardSync = NewCardSync()
+
+	if globals, err := DefaultCardSync.Globals.GetGlobals(models.DefaultLocale); err == nil {
+		registerFactions(globals)
+	}
+}
+
+// SetSyncStatus is the outcome of syncing one set
+type SetSyncStatus struct {
+	Set     string `json:"set"`
+	Updated int    `json:"updated"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CardSyncStatus describes the sync in progress, if any, and the last one to finish
+type CardSyncStatus struct {
+	Running      bool            `json:"running"`
+	LastStarted  time.Time       `json:"lastStarted"`
+	LastFinished time.Time       `json:"lastFinished"`
+	DurationMs   int64           `json:"durationMs"`
+	Version      string          `json:"version"`
+	Updated      int             `json:"updated"`
+	Sets         []SetSyncStatus `json:"sets"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// CardSync keeps the stored cards up to date with Data Dragon and records what each update changed
+type CardSync struct {
+	Cards         *models.CardModel
+	Globals       *models.GlobalsModel
+	Revisions     *models.CardRevisionModel
+	Decks         *models.DeckModel
+	AffectedDecks *models.AffectedDeckModel
+
+	statusLock sync.Mutex
+	status     CardSyncStatus
+}
+
+// NewCardSync creates a sync that works on the shared models
+func NewCardSync() *CardSync {
+	return &CardSync{
+		Cards:         models.Cards,
+		Globals:       models.Globals,
+		Revisions:     models.CardRevisions,
+		Decks:         models.Decks,
+		AffectedDecks: models.AffectedDecks,
+	}
+}
+
+// saveCardUpdates writes the cards that changed and a revision for each of them, returning how many changed
+func (s *CardSync) saveCardUpdates(cards []models.Card, version string) (int, error) {
+	updates := getCardsToUpdate(s.Cards, cards)
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	revisions := make([]models.CardRevision, 0, len(updates))
+	for _, card := range updates {
+		revision := models.NewCardRevision(s.Cards.GetCard(card.CardCode), card, version)
+		if revision.New || len(revision.Changes) > 0 {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	// The cards are written last, since the next sync diffs against them. If anything before fails, the next
+	// sync sees the same changes and records them again.
+	if err := s.Revisions.SaveRevisions(revisions); err != nil {
+		return 0, err
+	}
+	if err := s.flagAffectedDecks(revisions, version); err != nil {
+		return 0, err
+	}
+	if err := s.Cards.UpdateCards(updates); err != nil {
+		return 0, err
+	}
+
+	return len(updates), nil
+}
+
+// flagAffectedDecks badges the published decks using cards whose gameplay changed and records them for the version
+func (s *CardSync) flagAffectedDecks(revisions []models.CardRevision, version string) error {
+	var changedCards []string
+	for _, revision := range revisions {
+		if revision.AffectsGameplay() {
+			changedCards = append(changedCards, revision.CardID)
+		}
+	}
+
+	decks, err := s.Decks.FlagPatchAffectedDecks(changedCards, models.PatchAffectedBadge(version))
+	if err != nil {
+		return err
+	}
+
+	affected := make([]models.AffectedDeck, len(decks))
+	for i, deck := range decks {
+		affected[i] = models.NewAffectedDeck(deck, version, changedCards)
+	}
+	log.Printf("Flagged %v decks affected by changes to %v cards", len(decks), len(changedCards))
+
+	return s.AffectedDecks.SaveAffectedDecks(affected)
+}
+
+// Status returns a copy of the sync's current status
+func (s *CardSync) Status() CardSyncStatus {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+
+	status := s.status
+	status.Sets = append([]SetSyncStatus(nil), s.status.Sets...)
+	return status
+}
+
+// begin marks a sync as running, returning ErrSyncRunning if one already is
+func (s *CardSync) begin() error {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+
+	if s.status.Running {
+		return ErrSyncRunning
+	}
+
+	s.status.Running = true
+	s.status.LastStarted = time.Now()
+	return nil
+}
+
+func (s *CardSync) finish(version string, updated int, sets []SetSyncStatus, err error) {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+
+	started := s.status.LastStarted
+	finished := time.Now()
+	s.status = CardSyncStatus{
+		LastStarted:  started,
+		LastFinished: finished,
+		DurationMs:   finished.Sub(started).Nanoseconds() / int64(time.Millisecond),
+		Version:      version,
+		Updated:      updated,
+		Sets:         sets,
+	}
+	if err != nil {
+		s.status.Error = err.Error()
+	}
+}
+
+// StartUpdate runs UpdateAllSets in the background, returning ErrSyncRunning if a sync is already running
+func (s *CardSync) StartUpdate() error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.updateAllSets(); err != nil {
+			log.Println(err)
+		}
+	}()
+	return nil
+}
+
+// UpdateAllSets fetches every set in parallel and writes the changed cards in a single update, so the cache is
+// swapped once with all of the sets' changes. A set that can't be fetched is skipped and reported in the
+// returned error without holding up the others.
+func (s *CardSync) UpdateAllSets() error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+
+	return s.updateAllSets()
+}
+
+func (s *CardSync) updateAllSets() error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var cards []models.Card
+	var version string
+	var failed []string
+
+	setIDs := discoverSets(s.Globals)
+	sets := make([]SetSyncStatus, len(setIDs))
+
+	for i, set := range setIDs {
+		wg.Add(1)
+		go func(i int, set string) {
+			defer wg.Done()
+
+			sets[i].Set = set
+			setData, setVersion, err := getSetData(set, config.Config.Cards.Locales)
+			if err != nil {
+				log.Printf("Could not update %s: %s", set, err)
+				sets[i].Error = err.Error()
+			} else {
+				sets[i].Updated = len(getCardsToUpdate(s.Cards, setData))
+				log.Printf("Found %v updated cards for %s", sets[i].Updated, set)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err != nil {
+				failed = append(failed, set)
+				return
+			}
+
+			cards = append(cards, setData...)
+			if len(version) == 0 {
+				version = setVersion
+			}
+		}(i, set)
+	}
+	wg.Wait()
+
+	updated, err := s.saveCardUpdates(cards, version)
+	if err == nil {
+		log.Printf("Updated %v cards for version %s", updated, version)
+		if len(failed) > 0 {
+			err = errors.New("Could not update " + strings.Join(failed, ", "))
+		}
+	}
+
+	s.finish(version, updated, sets, err)
+	return err
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardSyncStatus(t *testing.T) {
+	cardSync := &CardSync{}
+
+	assert.Nil(t, cardSync.begin())
+	assert.True(t, cardSync.Status().Running)
+	assert.Equal(t, ErrSyncRunning, cardSync.begin())
+	assert.Equal(t, ErrSyncRunning, cardSync.StartUpdate())
+	assert.Equal(t, ErrSyncRunning, cardSync.UpdateAllSets())
+
+	sets := []SetSyncStatus{{Set: "set1", Updated: 3}, {Set: "set2", Error: "timeout"}}
+	cardSync.finish("1.10.0", 3, sets, errors.New("Could not update set2"))
+
+	status := cardSync.Status()
+	assert.False(t, status.Running)
+	assert.Equal(t, "1.10.0", status.Version)
+	assert.Equal(t, 3, status.Updated)
+	assert.Equal(t, sets, status.Sets)
+	assert.Equal(t, "Could not update set2", status.Error)
+	assert.False(t, status.LastFinished.Before(status.LastStarted))
+
+	status.Sets[0].Updated = 10
+	assert.Equal(t, 3, cardSync.Status().Sets[0].Updated)
+
+	assert.Nil(t, cardSync.begin())
+}
@@ -1,62 +1,111 @@
 package utils
 
 import (
-	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
-	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/db"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
 )
 
 const (
-	baseURL     string = "https://dd.b.pvp.net/latest/"
-	maxKnownSet int    = 5
+	baseURL      string = "https://dd.b.pvp.net/latest/"
+	maxKnownSet  int    = 5
+	fetchRetries int    = 3
+)
+
+var (
+	// dataDragonClient is used for every Data Dragon request, so a slow response can't hold up a sync forever
+	dataDragonClient = &http.Client{Timeout: 30 * time.Second}
+	// retryBackoff is the wait before the first retry, doubling after each failed attempt
+	retryBackoff = 2 * time.Second
+	setIDPattern = regexp.MustCompile(`^(?i)set(\d+)[a-z]*$`)
+	// versionPattern finds the version in asset paths like http://dd.b.pvp.net/1_10_0/set1/en_us/img/cards/01DE001.png
+	versionPattern = regexp.MustCompile(`pvp\.net/(\d+(?:_\d+)+)/`)
 )
 
 //Data Dragon Card - Structure received from Endpoint
 type DDCard struct {
-	AssociatedCardRefs    []string `json:"associatedCardRefs" bson:"associatedCardRefs"`
-	Region                string   `json:"region" bson:"region"`
-	Regions               []string `json:"regions" bson:"regions"`
-	RegionRef             string   `json:"regionRef" bson:"regionRef"`
-	Attack                int      `json:"attack" bson:"attack"`
-	Cost                  int      `json:"cost" bson:"cost"`
-	Health                int      `json:"health" bson:"health"`
-	Description           string   `json:"description" bson:"description"`
-	DescriptionRaw        string   `json:"descriptionRaw" bson:"descriptionRaw"`
-	LevelUpDescription    string   `json:"levelupDescription" bson:"levelupDescription"`
-	LevelUpDescriptionRaw string   `json:"levelupDescriptionRaw" bson:"levelupDescriptionRaw"`
-	FlavorText            string   `json:"flavorText" bson:"flavorText"`
-	ArtistName            string   `json:"artistName" bson:"artistName"`
-	Name                  string   `json:"name" bson:"name"`
-	CardCode              string   `json:"cardCode,omitempty" bson:"cardCode,omitempty"`
-	Keywords              []string `json:"keywords" bson:"keywords"`
-	KeywordRefs           []string `json:"keywordRefs" bson:"keywordRefs"`
-	SpellSpeed            string   `json:"spellSpeed" bson:"spellSpeed"`
-	SpellSpeedRef         string   `json:"spellSpeedRef" bson:"spellSpeedRef"`
-	Rarity                string   `json:"rarity" bson:"rarity"`
-	RarityRef             string   `json:"rarityRef" bson:"rarityRef"`
-	Subtype               string   `json:"subtype" bson:"subtype"`
-	Supertype             string   `json:"supertype" bson:"supertype"`
-	Type                  string   `json:"type" bson:"type"`
-	Collectible           bool     `json:"collectible" bson:"collectible"`
-	Set                   string   `json:"set" bson:"set"`
+	AssociatedCardRefs    []string  `json:"associatedCardRefs" bson:"associatedCardRefs"`
+	Region                string    `json:"region" bson:"region"`
+	Regions               []string  `json:"regions" bson:"regions"`
+	RegionRef             string    `json:"regionRef" bson:"regionRef"`
+	Attack                int       `json:"attack" bson:"attack"`
+	Cost                  int       `json:"cost" bson:"cost"`
+	Health                int       `json:"health" bson:"health"`
+	Description           string    `json:"description" bson:"description"`
+	DescriptionRaw        string    `json:"descriptionRaw" bson:"descriptionRaw"`
+	LevelUpDescription    string    `json:"levelupDescription" bson:"levelupDescription"`
+	LevelUpDescriptionRaw string    `json:"levelupDescriptionRaw" bson:"levelupDescriptionRaw"`
+	FlavorText            string    `json:"flavorText" bson:"flavorText"`
+	ArtistName            string    `json:"artistName" bson:"artistName"`
+	Name                  string    `json:"name" bson:"name"`
+	CardCode              string    `json:"cardCode,omitempty" bson:"cardCode,omitempty"`
+	Keywords              []string  `json:"keywords" bson:"keywords"`
+	KeywordRefs           []string  `json:"keywordRefs" bson:"keywordRefs"`
+	SpellSpeed            string    `json:"spellSpeed" bson:"spellSpeed"`
+	SpellSpeedRef         string    `json:"spellSpeedRef" bson:"spellSpeedRef"`
+	Rarity                string    `json:"rarity" bson:"rarity"`
+	RarityRef             string    `json:"rarityRef" bson:"rarityRef"`
+	Subtype               string    `json:"subtype" bson:"subtype"`
+	Supertype             string    `json:"supertype" bson:"supertype"`
+	Type                  string    `json:"type" bson:"type"`
+	Collectible           bool      `json:"collectible" bson:"collectible"`
+	Set                   string    `json:"set" bson:"set"`
+	Assets                []DDAsset `json:"assets" bson:"assets"`
+}
+
+type DDAsset struct {
+	GameAbsolutePath string `json:"gameAbsolutePath" bson:"gameAbsolutePath"`
+	FullAbsolutePath string `json:"fullAbsolutePath" bson:"fullAbsolutePath"`
+}
+
+// version reads the Data Dragon version, like 1.10.0, from the card's asset paths
+func (c DDCard) version() string {
+	for _, asset := range c.Assets {
+		if match := versionPattern.FindStringSubmatch(asset.GameAbsolutePath); match != nil {
+			return strings.Replace(match[1], "_", ".", -1)
+		}
+	}
+	return ""
+}
+
+// dataDragonVersion is the version of the first card in the set that has one
+func dataDragonVersion(ddCards []DDCard) string {
+	for _, card := range ddCards {
+		if version := card.version(); len(version) > 0 {
+			return version
+		}
+	}
+	return ""
+}
+
+// parseSetNumber reads the set number from set IDs like Set1, set12 or set6cde
+func parseSetNumber(set string) (int, error) {
+	match := setIDPattern.FindStringSubmatch(set)
+	if match == nil {
+		return 0, fmt.Errorf("Unknown set %s", set)
+	}
+
+	return strconv.Atoi(match[1])
 }
 
 func (c DDCard) getSetInteger() (int, error) {
-	setString := string(c.Set[len(c.Set)-1:])
-	return strconv.Atoi(setString)
+	return parseSetNumber(c.Set)
 }
 
 //Map to Card Type
-func (c *DDCard) toCard() models.Card {
+func (c *DDCard) toCard() (models.Card, error) {
 	cardSet, err := c.getSetInteger()
 	if err != nil {
-		log.Fatalln("Could not convert set string to integer")
+		return models.Card{}, fmt.Errorf("Card %s: %s", c.CardCode, err)
 	}
 
 	return models.Card{
@@ -87,44 +136,181 @@ func (c *DDCard) toCard() models.Card {
 		Type:                  c.Type,
 		Collectible:           c.Collectible,
 		CardSet:               cardSet,
+	}, nil
+}
+
+// toCardText keeps just the text of the card that changes between locales
+func (c *DDCard) toCardText() models.CardText {
+	return models.CardText{
+		Name:                  c.Name,
+		Description:           c.Description,
+		DescriptionRaw:        c.DescriptionRaw,
+		LevelUpDescription:    c.LevelUpDescription,
+		LevelUpDescriptionRaw: c.LevelUpDescriptionRaw,
+		FlavorText:            c.FlavorText,
+		Keywords:              c.Keywords,
+		SpellSpeed:            c.SpellSpeed,
+		Rarity:                c.Rarity,
+		Subtype:               c.Subtype,
+		Supertype:             c.Supertype,
+		Type:                  c.Type,
 	}
 }
 
-func getSetURL(set int) string {
-	setString := strconv.Itoa(set)
+func getSetURL(set string, locale string) string {
+	return baseURL + set + "/" + locale + "/data/" + set + "-" + locale + ".json"
+}
 
-	return baseURL + "set" + setString + "/en_us/data/set" + setString + "-en_us.json"
+func getGlobalsURL(locale string) string {
+	return baseURL + "core/" + locale + "/data/globals-" + locale + ".json"
 }
 
-func getSetData(set int) []models.Card {
-	setURL := getSetURL(set)
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	resp, err := http.Get(setURL)
-	if err != nil {
-		log.Fatalln(err)
+// withRetry calls fetch until it succeeds, waiting longer after each failure
+func withRetry(attempts int, fetch func() error) error {
+	backoff := retryBackoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fetch(); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Fatalln("Could not retrieve set data")
+	return err
+}
+
+// fetchJSON downloads and decodes a Data Dragon file, retrying on failure
+func fetchJSON(url string, target interface{}) error {
+	return withRetry(fetchRetries, func() error {
+		resp, err := dataDragonClient.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("Could not retrieve %s: %s", url, resp.Status)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(body, target)
+	})
+}
+
+func fetchSet(set string, locale string) ([]DDCard, error) {
+	var ddCards []DDCard
+	err := fetchJSON(getSetURL(set, locale), &ddCards)
+	return ddCards, err
+}
+
+// addLocalizedText stores the translated text of each card under the locale
+func addLocalizedText(cards []models.Card, locale string, translated []DDCard) {
+	positions := make(map[string]int, len(cards))
+	for i, card := range cards {
+		positions[card.CardCode] = i
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalln(err)
+	for _, ddCard := range translated {
+		i, ok := positions[ddCard.CardCode]
+		if !ok {
+			continue
+		}
+
+		if cards[i].Localized == nil {
+			cards[i].Localized = make(map[string]models.CardText)
+		}
+		cards[i].Localized[locale] = ddCard.toCardText()
 	}
+}
 
-	var ddCards []DDCard
-	err = json.Unmarshal(body, &ddCards)
+// getSetData fetches the set in the default locale and adds the text from every other configured locale.
+// It also returns the set's Data Dragon version.
+func getSetData(set string, locales []string) ([]models.Card, string, error) {
+	ddCards, err := fetchSet(set, models.DefaultLocale)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, "", err
 	}
 
 	cards := make([]models.Card, len(ddCards))
-	for i, card := range ddCards {
-		cards[i] = card.toCard()
+	for i, ddCard := range ddCards {
+		if cards[i], err = ddCard.toCard(); err != nil {
+			return nil, "", err
+		}
 	}
 
-	return cards
+	for _, locale := range locales {
+		if locale == models.DefaultLocale {
+			continue
+		}
+
+		translated, err := fetchSet(set, locale)
+		if err != nil {
+			return nil, "", err
+		}
+		addLocalizedText(cards, locale, translated)
+	}
+
+	return cards, dataDragonVersion(ddCards), nil
+}
+
+// setIDsFromGlobals lists the card sets in the globals, in the lower case form used in Data Dragon URLs
+func setIDsFromGlobals(globals *models.GameGlobals) []string {
+	var sets []string
+	for _, set := range globals.Sets {
+		if setIDPattern.MatchString(set.NameRef) {
+			sets = append(sets, strings.ToLower(set.NameRef))
+		}
+	}
+	return sets
+}
+
+// registerFactions makes regions added to Data Dragon known to the deck encoder
+func registerFactions(globals *models.GameGlobals) {
+	if skipped := globals.RegisterFactions(config.Config.Cards.FactionIDs); len(skipped) > 0 {
+		log.Printf("No deckcode faction id configured for regions %s", strings.Join(skipped, ", "))
+	}
+}
+
+func defaultSetIDs() []string {
+	sets := make([]string, maxKnownSet)
+	for i := range sets {
+		sets[i] = "set" + strconv.Itoa(i+1)
+	}
+	return sets
+}
+
+// discoverSets reads the available sets from the core bundle's globals, which are saved for next time.
+// If they can't be downloaded the last saved globals are used, and failing that the sets known when this was written.
+func discoverSets(globalsModel *models.GlobalsModel) []string {
+	var globals models.GameGlobals
+	err := fetchJSON(getGlobalsURL(models.DefaultLocale), &globals)
+	if err == nil {
+		globals.Locale = models.DefaultLocale
+		if err := globalsModel.SaveGlobals(globals); err != nil {
+			log.Printf("Could not save globals: %s", err)
+		}
+	} else {
+		log.Printf("Could not retrieve globals, using saved sets: %s", err)
+		saved, err := globalsModel.GetGlobals(models.DefaultLocale)
+		if err != nil {
+			return defaultSetIDs()
+		}
+		globals = *saved
+	}
+
+	registerFactions(&globals)
+
+	if sets := setIDsFromGlobals(&globals); len(sets) > 0 {
+		return sets
+	}
+	return defaultSetIDs()
 }
 
 func hasCardChanged(model *models.CardModel, card models.Card) bool {
@@ -144,26 +330,3 @@ func getCardsToUpdate(model *models.CardModel, cards []models.Card) []models.Car
 
 	return updatedCards
 }
-
-func updateSet(model *models.CardModel, set int) {
-	setData := getSetData(set)
-	setUpdates := getCardsToUpdate(model, setData)
-	if len(setUpdates) > 0 {
-		model.UpdateCards(setUpdates)
-	}
-	log.Printf("Updated %v cards for Set %v", len(setUpdates), set)
-}
-
-func UpdateAllSets(db *db.Database) {
-	db.WaitForConnection()
-	collection := db.Collection("cards")
-	model := models.NewCardModel(collection)
-	if len(model.Cards) == 0 {
-		model.CacheCards()
-	}
-
-	for i := 1; i <= maxKnownSet; i++ {
-		go updateSet(model, i)
-	}
-
-}
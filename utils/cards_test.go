@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
@@ -9,9 +11,29 @@ import (
 
 func TestGetSetURL(t *testing.T) {
 	expected := "https://dd.b.pvp.net/latest/set4/en_us/data/set4-en_us.json"
-	received := getSetURL(4)
+	received := getSetURL("set4", "en_us")
 
 	assert.Equal(t, expected, received)
+
+	expected = "https://dd.b.pvp.net/latest/set4/ko_kr/data/set4-ko_kr.json"
+	received = getSetURL("set4", "ko_kr")
+
+	assert.Equal(t, expected, received)
+}
+
+func TestAddLocalizedText(t *testing.T) {
+	cards := []models.Card{{ID: "01FR024", CardCode: "01FR024", Name: "Ashe"}}
+	translated := []DDCard{
+		{CardCode: "01FR024", Name: "애쉬", Keywords: []string{"빙결"}},
+		{CardCode: "doesntexist", Name: "Unknown"},
+	}
+
+	addLocalizedText(cards, "ko_kr", translated)
+
+	assert.Equal(t, "Ashe", cards[0].Name)
+	assert.Equal(t, "애쉬", cards[0].Localized["ko_kr"].Name)
+	assert.Equal(t, []string{"빙결"}, cards[0].Localized["ko_kr"].Keywords)
+	assert.Equal(t, 1, len(cards[0].Localized))
 }
 
 func TestGetSetInteger(t *testing.T) {
@@ -22,30 +44,105 @@ func TestGetSetInteger(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, expected, received)
+
+	card = DDCard{Set: "Set12"}
+	received, err = card.getSetInteger()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 12, received)
+
+	card = DDCard{Set: "set6cde"}
+	received, err = card.getSetInteger()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6, received)
+
+	card = DDCard{Set: "SetEvent"}
+	_, err = card.getSetInteger()
+
+	assert.NotNil(t, err)
+}
+
+func TestToCardUnknownSet(t *testing.T) {
+	card := DDCard{CardCode: "01FR024", Set: ""}
+	_, err := card.toCard()
+
+	assert.NotNil(t, err)
+}
+
+func TestWithRetry(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = 2 * time.Second }()
+
+	attempts := 0
+	err := withRetry(3, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = withRetry(2, func() error {
+		attempts++
+		return errors.New("permanent failure")
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestSetIDsFromGlobals(t *testing.T) {
+	globals := models.GameGlobals{Sets: []models.GlobalSet{
+		{NameRef: "Set1"},
+		{NameRef: "Set6cde"},
+		{NameRef: "SetEvent"},
+	}}
+
+	assert.Equal(t, []string{"set1", "set6cde"}, setIDsFromGlobals(&globals))
+	assert.Equal(t, "set5", defaultSetIDs()[4])
 }
 
 func TestGetSetData(t *testing.T) {
-	data := getSetData(1)
+	data, version, err := getSetData("set1", []string{"en_us", "fr_fr"})
+	assert.Nil(t, err)
 
 	expected := 1
 	received := data[0].CardSet
 
 	assert.Equal(t, expected, received)
+	assert.NotEmpty(t, data[0].Localized["fr_fr"].Name)
+	assert.NotEmpty(t, version)
+}
+
+func TestDataDragonVersion(t *testing.T) {
+	cards := []DDCard{
+		{CardCode: "01DE001"},
+		{CardCode: "01DE002", Assets: []DDAsset{{GameAbsolutePath: "http://dd.b.pvp.net/1_10_0/set1/en_us/img/cards/01DE002.png"}}},
+	}
+
+	assert.Equal(t, "1.10.0", dataDragonVersion(cards))
+	assert.Equal(t, "", dataDragonVersion(cards[:1]))
 }
 
 func TestHasCardChanged(t *testing.T) {
 	cards := make([]models.Card, 1)
 	cards[0] = models.Card{ID: "test", CardCode: "test"}
 
-	model := models.CardModel{Cards: cards}
+	model := models.NewCardModel(nil)
+	model.SetCards(cards)
 
-	shouldNotHaveChanged := hasCardChanged(&model, cards[0])
+	shouldNotHaveChanged := hasCardChanged(model, cards[0])
 
 	assert.False(t, shouldNotHaveChanged)
 
 	changedCard := models.Card{ID: "test2", CardCode: "test"}
 
-	shouldHaveChanged := hasCardChanged(&model, changedCard)
+	shouldHaveChanged := hasCardChanged(model, changedCard)
 	assert.True(t, shouldHaveChanged)
 }
 
@@ -55,9 +152,10 @@ func TestUpdateSetData(t *testing.T) {
 	savedCards[0] = models.Card{ID: "test", CardCode: "test"}
 	cardUpdates[0] = models.Card{ID: "test2", CardCode: "test"}
 
-	model := models.CardModel{Cards: savedCards}
+	model := models.NewCardModel(nil)
+	model.SetCards(savedCards)
 
-	updatedCards := getCardsToUpdate(&model, cardUpdates)
+	updatedCards := getCardsToUpdate(model, cardUpdates)
 
 	assert.Equal(t, cardUpdates[0], updatedCards[0])
 }
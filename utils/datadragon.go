@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+var (
+	setFilePattern     = regexp.MustCompile(`(?:^|/)data/(set[0-9a-z]+)-([a-z]{2}_[a-z]{2})\.json$`)
+	globalsFilePattern = regexp.MustCompile(`(?:^|/)data/globals-([a-z]{2}_[a-z]{2})\.json$`)
+)
+
+// Bundle holds the data read from Data Dragon set and core bundles, keyed by set and locale
+type Bundle struct {
+	Sets    map[string]map[string][]DDCard
+	Globals map[string]models.GameGlobals
+}
+
+func newBundle() *Bundle {
+	return &Bundle{
+		Sets:    make(map[string]map[string][]DDCard),
+		Globals: make(map[string]models.GameGlobals),
+	}
+}
+
+// ReadBundles reads Data Dragon bundles from zip files or extracted directories
+func ReadBundles(paths []string) (*Bundle, error) {
+	bundle := newBundle()
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			err = bundle.readDirectory(path)
+		} else {
+			err = bundle.readZip(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Could not read %s: %s", path, err)
+		}
+	}
+
+	return bundle, nil
+}
+
+func (b *Bundle) readZip(path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if !b.wants(file.Name) {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = b.readFile(file.Name, reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bundle) readDirectory(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		name := filepath.ToSlash(path)
+		if !b.wants(name) {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return b.readFile(name, file)
+	})
+}
+
+func (b *Bundle) wants(name string) bool {
+	return setFilePattern.MatchString(name) || globalsFilePattern.MatchString(name)
+}
+
+func (b *Bundle) readFile(name string, reader io.Reader) error {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if match := globalsFilePattern.FindStringSubmatch(name); match != nil {
+		var globals models.GameGlobals
+		if err := json.Unmarshal(data, &globals); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		globals.Locale = match[1]
+		b.Globals[match[1]] = globals
+		return nil
+	}
+
+	match := setFilePattern.FindStringSubmatch(name)
+	var ddCards []DDCard
+	if err := json.Unmarshal(data, &ddCards); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	set, locale := match[1], match[2]
+	if b.Sets[set] == nil {
+		b.Sets[set] = make(map[string][]DDCard)
+	}
+	b.Sets[set][locale] = ddCards
+
+	return nil
+}
+
+// Cards builds the cards of every set in the bundle, with the text of each locale that was read.
+// Every set needs its default locale file, since that is where the card itself comes from.
+func (b *Bundle) Cards() ([]models.Card, error) {
+	var sets []string
+	for set := range b.Sets {
+		sets = append(sets, set)
+	}
+	sort.Strings(sets)
+
+	var cards []models.Card
+	for _, set := range sets {
+		locales := b.Sets[set]
+		ddCards, ok := locales[models.DefaultLocale]
+		if !ok {
+			return nil, fmt.Errorf("%s has no %s data", set, models.DefaultLocale)
+		}
+
+		setCards := make([]models.Card, len(ddCards))
+		for i, card := range ddCards {
+			var err error
+			if setCards[i], err = card.toCard(); err != nil {
+				return nil, err
+			}
+		}
+
+		for locale, translated := range locales {
+			if locale != models.DefaultLocale {
+				addLocalizedText(setCards, locale, translated)
+			}
+		}
+
+		cards = append(cards, setCards...)
+	}
+
+	return cards, nil
+}
+
+// Version is the Data Dragon version of the bundle's cards
+func (b *Bundle) Version() string {
+	for _, locales := range b.Sets {
+		if version := dataDragonVersion(locales[models.DefaultLocale]); len(version) > 0 {
+			return version
+		}
+	}
+	return ""
+}
+
+// Import saves the cards and globals from Data Dragon bundles, returning how many cards changed
+func (s *CardSync) Import(paths []string) (int, error) {
+	bundle, err := ReadBundles(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	cards, err := bundle.Cards()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, globals := range bundle.Globals {
+		if err := s.Globals.SaveGlobals(globals); err != nil {
+			return 0, err
+		}
+		registerFactions(&globals)
+	}
+
+	return s.saveCardUpdates(cards, bundle.Version())
+}
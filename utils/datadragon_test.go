@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSetEnglish = `[{"cardCode": "01FR024", "name": "Ashe", "region": "Freljord", "regionRef": "Freljord", "set": "Set1", "collectible": true}]`
+	testSetKorean  = `[{"cardCode": "01FR024", "name": "애쉬", "region": "프렐요드", "regionRef": "Freljord", "set": "Set1", "collectible": true}]`
+	testGlobals    = `{"regions": [{"name": "Freljord", "nameRef": "Freljord", "abbreviation": "FR"}], "keywords": [{"name": "Frostbite", "nameRef": "Frostbite", "description": "Set a unit's Power to 0 this round."}]}`
+)
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, contents := range files {
+		f, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(contents))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadBundles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datadragon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	setZip := filepath.Join(dir, "set1-en_us.zip")
+	writeTestZip(t, setZip, map[string]string{
+		"metadata.json":                "{}",
+		"en_us/data/set1-en_us.json":   testSetEnglish,
+		"en_us/img/cards/01FR024.json": "not card data",
+	})
+
+	extracted := filepath.Join(dir, "core")
+	os.MkdirAll(filepath.Join(extracted, "ko_kr", "data"), 0755)
+	ioutil.WriteFile(filepath.Join(extracted, "ko_kr", "data", "set1-ko_kr.json"), []byte(testSetKorean), 0644)
+	ioutil.WriteFile(filepath.Join(extracted, "ko_kr", "data", "globals-ko_kr.json"), []byte(testGlobals), 0644)
+
+	bundle, err := ReadBundles([]string{setZip, extracted})
+	assert.Nil(t, err)
+	assert.Equal(t, "Freljord", bundle.Globals["ko_kr"].Regions[0].NameRef)
+	assert.Equal(t, "ko_kr", bundle.Globals["ko_kr"].Locale)
+
+	cards, err := bundle.Cards()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cards))
+	assert.Equal(t, "Ashe", cards[0].Name)
+	assert.Equal(t, 1, cards[0].CardSet)
+	assert.Equal(t, "애쉬", cards[0].Localized["ko_kr"].Name)
+}
+
+func TestReadBundlesErrors(t *testing.T) {
+	_, err := ReadBundles([]string{"doesntexist.zip"})
+	assert.NotNil(t, err)
+
+	dir, err := ioutil.TempDir("", "datadragon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "ko_kr", "data"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "ko_kr", "data", "set1-ko_kr.json"), []byte(testSetKorean), 0644)
+
+	bundle, err := ReadBundles([]string{dir})
+	assert.Nil(t, err)
+
+	_, err = bundle.Cards()
+	assert.NotNil(t, err)
+}
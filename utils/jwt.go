@@ -1,69 +1,139 @@
 package utils
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/labstack/echo"
-	"github.com/labstack/echo/middleware"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
 )
 
-const jwtSecret = "a;W>)@$p9f3Ugn!"
+const (
+	accessTokenCookie   = "authtoken"
+	refreshTokenCookie  = "refreshtoken"
+	accessTokenLifetime = 15 * time.Minute
+)
+
+var keys *KeySet
+
+// InitJWT loads the keys auth tokens are signed and verified with. It fails if none are configured.
+func InitJWT(cfg config.JWTConfig) error {
+	keySet, err := LoadKeySet(cfg)
+	if err != nil {
+		return fmt.Errorf("Could not load JWT keys: %s", err)
+	}
+	keys = keySet
+	return nil
+}
 
 type Claims struct {
 	*jwt.StandardClaims
-	models.User
+	models.PublicUser
+	SessionID string `json:"sid"`
 }
 
 func getExpiry() time.Time {
-	return time.Now().Add(time.Hour * 24)
+	return time.Now().Add(accessTokenLifetime)
 }
 
-func DecodeToken(token string) (*models.User, error) {
-	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
+func decodeClaims(token string) (*Claims, error) {
+	parsed, err := keys.Parse(token, &Claims{})
+	if err != nil {
+		return nil, err
+	}
 
+	return parsed.Claims.(*Claims), nil
+}
+
+// DecodeToken checks the token's signature and expiry. It does not check whether the token's session has been revoked.
+func DecodeToken(token string) (*models.PublicUser, error) {
+	claims, err := decodeClaims(token)
 	if err != nil {
 		return nil, err
 	}
 
-	claims := parsed.Claims.(*Claims)
+	return &claims.PublicUser, nil
+}
+
+// authenticateToken decodes the token and makes sure its session is still active
+func authenticateToken(token string) (*Claims, error) {
+	claims, err := decodeClaims(token)
+	if err != nil {
+		return nil, types.Unauthorized("Could not decode auth token")
+	}
+
+	active, err := models.Sessions.IsSessionActive(claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, types.Unauthorized("Session has expired or been revoked")
+	}
 
-	return &claims.User, nil
+	return claims, nil
 }
 
-func CreateToken(user models.User) (string, error) {
-	t := jwt.New(jwt.SigningMethodHS256)
-	t.Claims = &Claims{
+func CreateToken(user models.PublicUser, sessionID string) (string, error) {
+	claims := &Claims{
 		&jwt.StandardClaims{
 			ExpiresAt: getExpiry().Unix(),
 		},
 		user,
+		sessionID,
 	}
 
-	return t.SignedString([]byte(jwtSecret))
+	return keys.Sign(claims)
 }
 
-func CreateJWTCookie(user models.User) *http.Cookie {
+func CreateJWTCookie(user models.PublicUser, sessionID string) *http.Cookie {
 	cookie := new(http.Cookie)
-	cookie.Name = "authtoken"
-	token, err := CreateToken(user)
+	cookie.Name = accessTokenCookie
+	token, err := CreateToken(user, sessionID)
 	if err != nil {
 		return nil
 	}
 
 	cookie.Value = token
 	cookie.Expires = getExpiry()
+	cookie.Path = "/"
+	cookie.HttpOnly = true
 
 	return cookie
 }
 
-func GetJWTCookie(cookies []*http.Cookie) *http.Cookie {
+// CreateRefreshCookie only sends the refresh token to the user routes, which is where it gets exchanged or revoked
+func CreateRefreshCookie(refreshToken string) *http.Cookie {
+	return &http.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    refreshToken,
+		Expires:  time.Now().Add(models.SessionLifetime),
+		Path:     "/users",
+		HttpOnly: true,
+	}
+}
+
+func ClearAuthCookies(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:    accessTokenCookie,
+		Value:   "",
+		Expires: time.Unix(0, 0),
+		Path:    "/",
+	})
+	c.SetCookie(&http.Cookie{
+		Name:    refreshTokenCookie,
+		Value:   "",
+		Expires: time.Unix(0, 0),
+		Path:    "/users",
+	})
+}
+
+func getCookie(cookies []*http.Cookie, name string) *http.Cookie {
 	for _, cookie := range cookies {
-		if cookie.Name == "authtoken" {
+		if cookie.Name == name {
 			return cookie
 		}
 	}
@@ -71,9 +141,63 @@ func GetJWTCookie(cookies []*http.Cookie) *http.Cookie {
 	return nil
 }
 
+func GetJWTCookie(cookies []*http.Cookie) *http.Cookie {
+	return getCookie(cookies, accessTokenCookie)
+}
+
+func GetRefreshCookie(cookies []*http.Cookie) *http.Cookie {
+	return getCookie(cookies, refreshTokenCookie)
+}
+
+// requestClaims returns the claims JWTMiddleware stored on the context, or authenticates the request's cookie when the middleware hasn't run
+func requestClaims(c echo.Context) (*Claims, error) {
+	if token, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(*Claims); ok {
+			return claims, nil
+		}
+	}
+
+	cookie := GetJWTCookie(c.Cookies())
+	if cookie == nil {
+		return nil, types.Unauthorized("Invalid auth token")
+	}
+
+	return authenticateToken(cookie.Value)
+}
+
 func JWTMiddleware() echo.MiddlewareFunc {
-	return middleware.JWTWithConfig(middleware.JWTConfig{
-		SigningKey:  []byte(jwtSecret),
-		TokenLookup: "cookie:authtoken",
-	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cookie := GetJWTCookie(c.Cookies())
+			if cookie == nil {
+				return types.Unauthorized("Invalid auth token")
+			}
+
+			claims, err := authenticateToken(cookie.Value)
+			if err != nil {
+				return err
+			}
+
+			c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+			return next(c)
+		}
+	}
+}
+
+func UserFromContext(c echo.Context) (*models.PublicUser, error) {
+	claims, err := requestClaims(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims.PublicUser, nil
+}
+
+func SessionFromContext(c echo.Context) (string, error) {
+	claims, err := requestClaims(c)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.SessionID, nil
 }
@@ -2,17 +2,26 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
 	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+func init() {
+	if err := InitJWT(config.TestConfig.JWT); err != nil {
+		panic(err)
+	}
+}
+
 func TestJWT(t *testing.T) {
-	user := models.User{
+	user := models.PublicUser{
 		Username: "testuser",
 	}
 
-	token, err := CreateToken(user)
+	token, err := CreateToken(user, "session")
 	assert.Nil(t, err)
 	assert.NotNil(t, token)
 
@@ -20,3 +29,35 @@ func TestJWT(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, &user, decoded)
 }
+
+func TestActionToken(t *testing.T) {
+	userToken := models.UserToken{
+		ID:          primitive.NewObjectID(),
+		UserID:      "user",
+		Purpose:     models.PurposePasswordReset,
+		DateExpires: time.Now().Add(time.Hour),
+	}
+
+	token, err := CreateActionToken(userToken)
+	assert.Nil(t, err)
+
+	tokenID, err := DecodeActionToken(token, models.PurposePasswordReset)
+	assert.Nil(t, err)
+	assert.Equal(t, userToken.TokenID(), tokenID)
+
+	_, err = DecodeActionToken(token, models.PurposeEmailVerification)
+	assert.NotNil(t, err)
+
+	accessToken, err := CreateToken(models.PublicUser{Username: "testuser"}, "session")
+	assert.Nil(t, err)
+
+	_, err = DecodeActionToken(accessToken, models.PurposePasswordReset)
+	assert.NotNil(t, err)
+
+	userToken.DateExpires = time.Now().Add(-time.Hour)
+	expiredToken, err := CreateActionToken(userToken)
+	assert.Nil(t, err)
+
+	_, err = DecodeActionToken(expiredToken, models.PurposePasswordReset)
+	assert.NotNil(t, err)
+}
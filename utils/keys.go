@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+)
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeySet holds every key auth tokens may have been signed with, keyed by kid, and the one new tokens are signed with
+type KeySet struct {
+	current *signingKey
+	keys    map[string]*signingKey
+}
+
+func LoadKeySet(cfg config.JWTConfig) (*KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("No JWT keys are configured")
+	}
+
+	keySet := &KeySet{
+		keys: make(map[string]*signingKey),
+	}
+
+	for _, keyConfig := range cfg.Keys {
+		key, err := loadSigningKey(keyConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := keySet.keys[key.id]; exists {
+			return nil, fmt.Errorf("JWT key %s is configured more than once", key.id)
+		}
+		keySet.keys[key.id] = key
+	}
+
+	current, ok := keySet.keys[cfg.SigningKey]
+	if !ok {
+		return nil, fmt.Errorf("JWT signing key %s is not configured", cfg.SigningKey)
+	}
+	if current.signKey == nil {
+		return nil, fmt.Errorf("JWT signing key %s has no private key", cfg.SigningKey)
+	}
+	keySet.current = current
+
+	return keySet, nil
+}
+
+func loadSigningKey(cfg config.JWTKeyConfig) (*signingKey, error) {
+	if len(cfg.ID) == 0 {
+		return nil, errors.New("JWT keys must have an id")
+	}
+
+	key := &signingKey{id: cfg.ID}
+	var err error
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		secret, err := loadSecret(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("Could not load JWT key %s: %s", cfg.ID, err)
+		}
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("JWT key %s has no secret", cfg.ID)
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(secret)
+		key.verifyKey = []byte(secret)
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		err = loadRSAKeys(key, cfg)
+	case SigningMethodEdDSA.Alg():
+		key.method = SigningMethodEdDSA
+		err = loadEdDSAKeys(key, cfg)
+	default:
+		return nil, fmt.Errorf("JWT key %s uses unsupported algorithm %s", cfg.ID, cfg.Algorithm)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not load JWT key %s: %s", cfg.ID, err)
+	}
+	if key.verifyKey == nil {
+		return nil, fmt.Errorf("JWT key %s needs a private or public key file", cfg.ID)
+	}
+
+	return key, nil
+}
+
+// loadSecret reads an HS256 secret from the config, the environment variable named by SecretEnv, or SecretFile
+func loadSecret(cfg config.JWTKeyConfig) (string, error) {
+	if len(cfg.Secret) > 0 {
+		return cfg.Secret, nil
+	}
+	if len(cfg.SecretEnv) > 0 {
+		return os.Getenv(cfg.SecretEnv), nil
+	}
+	if len(cfg.SecretFile) > 0 {
+		data, err := ioutil.ReadFile(cfg.SecretFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
+
+func loadRSAKeys(key *signingKey, cfg config.JWTKeyConfig) error {
+	if len(cfg.PrivateKeyFile) > 0 {
+		data, err := ioutil.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return err
+		}
+
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		key.signKey = privateKey
+		key.verifyKey = &privateKey.PublicKey
+	}
+
+	if len(cfg.PublicKeyFile) > 0 {
+		data, err := ioutil.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return err
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		key.verifyKey = publicKey
+	}
+
+	return nil
+}
+
+func loadEdDSAKeys(key *signingKey, cfg config.JWTKeyConfig) error {
+	if len(cfg.PrivateKeyFile) > 0 {
+		parsed, err := parsePEMFile(cfg.PrivateKeyFile, func(der []byte) (interface{}, error) {
+			return x509.ParsePKCS8PrivateKey(der)
+		})
+		if err != nil {
+			return err
+		}
+
+		privateKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return errors.New("private key is not an Ed25519 key")
+		}
+		key.signKey = privateKey
+		key.verifyKey = privateKey.Public().(ed25519.PublicKey)
+	}
+
+	if len(cfg.PublicKeyFile) > 0 {
+		parsed, err := parsePEMFile(cfg.PublicKeyFile, x509.ParsePKIXPublicKey)
+		if err != nil {
+			return err
+		}
+
+		publicKey, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("public key is not an Ed25519 key")
+		}
+		key.verifyKey = publicKey
+	}
+
+	return nil
+}
+
+func parsePEMFile(path string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+
+	return parse(block.Bytes)
+}
+
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.method, claims)
+	token.Header["kid"] = k.current.id
+
+	return token.SignedString(k.current.signKey)
+}
+
+// Keyfunc picks the verification key from the token's kid. Tokens issued before kids were added are checked against the current signing key.
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key := k.current
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("Unknown signing key %s", kid)
+		}
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("Unexpected signing method %s", token.Method.Alg())
+	}
+
+	return key.verifyKey, nil
+}
+
+func (k *KeySet) Parse(token string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, claims, k.Keyfunc)
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/config"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKeys, err := LoadKeySet(config.JWTConfig{
+		SigningKey: "old",
+		Keys:       []config.JWTKeyConfig{{ID: "old", Algorithm: "HS256", Secret: "old secret"}},
+	})
+	assert.Nil(t, err)
+
+	token, err := oldKeys.Sign(&jwt.StandardClaims{Subject: "user"})
+	assert.Nil(t, err)
+
+	rotatedKeys, err := LoadKeySet(config.JWTConfig{
+		SigningKey: "new",
+		Keys: []config.JWTKeyConfig{
+			{ID: "new", Algorithm: "HS256", Secret: "new secret"},
+			{ID: "old", Algorithm: "HS256", Secret: "old secret"},
+		},
+	})
+	assert.Nil(t, err)
+
+	claims := &jwt.StandardClaims{}
+	_, err = rotatedKeys.Parse(token, claims)
+	assert.Nil(t, err)
+	assert.Equal(t, "user", claims.Subject)
+
+	retiredKeys, err := LoadKeySet(config.JWTConfig{
+		SigningKey: "new",
+		Keys:       []config.JWTKeyConfig{{ID: "new", Algorithm: "HS256", Secret: "new secret"}},
+	})
+	assert.Nil(t, err)
+
+	_, err = retiredKeys.Parse(token, &jwt.StandardClaims{})
+	assert.NotNil(t, err)
+}
+
+func TestLoadKeySetErrors(t *testing.T) {
+	_, err := LoadKeySet(config.JWTConfig{SigningKey: "missing"})
+	assert.NotNil(t, err)
+
+	_, err = LoadKeySet(config.JWTConfig{
+		SigningKey: "key",
+		Keys:       []config.JWTKeyConfig{{ID: "key", Algorithm: "none"}},
+	})
+	assert.NotNil(t, err)
+
+	_, err = LoadKeySet(config.JWTConfig{
+		SigningKey: "key",
+		Keys:       []config.JWTKeyConfig{{ID: "key", Algorithm: "HS256"}},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestSecretSources(t *testing.T) {
+	_, err := LoadKeySet(config.JWTConfig{})
+	assert.NotNil(t, err)
+
+	os.Setenv("DORUNETERRA_TEST_JWT_SECRET", "env secret")
+	defer os.Unsetenv("DORUNETERRA_TEST_JWT_SECRET")
+
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secretFile := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(secretFile, []byte("file secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := LoadKeySet(config.JWTConfig{
+		SigningKey: "env",
+		Keys: []config.JWTKeyConfig{
+			{ID: "env", Algorithm: "HS256", SecretEnv: "DORUNETERRA_TEST_JWT_SECRET"},
+			{ID: "file", Algorithm: "HS256", SecretFile: secretFile},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("env secret"), keySet.keys["env"].signKey)
+	assert.Equal(t, []byte("file secret"), keySet.keys["file"].signKey)
+
+	_, err = LoadKeySet(config.JWTConfig{
+		SigningKey: "env",
+		Keys:       []config.JWTKeyConfig{{ID: "env", Algorithm: "HS256", SecretEnv: "DORUNETERRA_TEST_UNSET_SECRET"}},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestAsymmetricKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwtkeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPrivateDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyConfigs := []config.JWTKeyConfig{
+		{ID: "rsa", Algorithm: "RS256", PrivateKeyFile: writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))},
+		{ID: "rsa-public", Algorithm: "RS256", PublicKeyFile: writePEM(t, dir, "rsa.pub", "PUBLIC KEY", rsaPublic)},
+		{ID: "ed", Algorithm: "EdDSA", PrivateKeyFile: writePEM(t, dir, "ed.pem", "PRIVATE KEY", edPrivateDER)},
+		{ID: "ed-public", Algorithm: "EdDSA", PublicKeyFile: writePEM(t, dir, "ed.pub", "PUBLIC KEY", edPublicDER)},
+	}
+
+	for _, signingKey := range []string{"rsa", "ed"} {
+		keySet, err := LoadKeySet(config.JWTConfig{SigningKey: signingKey, Keys: keyConfigs})
+		assert.Nil(t, err)
+
+		token, err := keySet.Sign(&jwt.StandardClaims{Subject: signingKey})
+		assert.Nil(t, err)
+
+		claims := &jwt.StandardClaims{}
+		_, err = keySet.Parse(token, claims)
+		assert.Nil(t, err)
+		assert.Equal(t, signingKey, claims.Subject)
+	}
+
+	_, err = LoadKeySet(config.JWTConfig{SigningKey: "rsa-public", Keys: keyConfigs})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+)
+
+// normalizeLocale turns language tags like ko-KR into the Data Dragon form ko_kr
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "-", "_", -1))
+}
+
+// matchLocale finds the supported locale for the tag, falling back to the first one in the same language
+func matchLocale(locale string, supported []string) (string, bool) {
+	locale = normalizeLocale(locale)
+	if len(locale) == 0 {
+		return "", false
+	}
+
+	for _, s := range supported {
+		if s == locale {
+			return s, true
+		}
+	}
+
+	language := strings.SplitN(locale, "_", 2)[0]
+	for _, s := range supported {
+		if strings.SplitN(s, "_", 2)[0] == language {
+			return s, true
+		}
+	}
+
+	return "", false
+}
+
+type weightedLocale struct {
+	locale string
+	weight float64
+}
+
+// parseAcceptLanguage returns the header's language tags, most preferred first
+func parseAcceptLanguage(header string) []string {
+	var weighted []weightedLocale
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		locale := strings.TrimSpace(fields[0])
+		if len(locale) == 0 || locale == "*" {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		if weight > 0 {
+			weighted = append(weighted, weightedLocale{locale, weight})
+		}
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].weight > weighted[j].weight
+	})
+
+	locales := make([]string, len(weighted))
+	for i, w := range weighted {
+		locales[i] = w.locale
+	}
+	return locales
+}
+
+// ResolveLocale picks the locale to respond in from an explicit locale parameter, then the Accept-Language header,
+// falling back to the default locale when neither is supported
+func ResolveLocale(requested, acceptLanguage string, supported []string) string {
+	if locale, ok := matchLocale(requested, supported); ok {
+		return locale
+	}
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if locale, ok := matchLocale(tag, supported); ok {
+			return locale
+		}
+	}
+
+	return models.DefaultLocale
+}
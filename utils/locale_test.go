@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLocale(t *testing.T) {
+	supported := []string{"en_us", "es_es", "es_mx", "ko_kr"}
+
+	assert.Equal(t, "ko_kr", ResolveLocale("ko_kr", "", supported))
+	assert.Equal(t, "es_mx", ResolveLocale("es-MX", "ko", supported))
+	assert.Equal(t, "ko_kr", ResolveLocale("", "ko-KR,ko;q=0.9,en-US;q=0.8", supported))
+	assert.Equal(t, "es_es", ResolveLocale("", "fr-FR;q=0.9, es;q=0.8", supported))
+	assert.Equal(t, "ko_kr", ResolveLocale("xx_xx", "en;q=0.5, ko;q=0.7", supported))
+	assert.Equal(t, "en_us", ResolveLocale("", "fr-FR, *;q=0.5", supported))
+	assert.Equal(t, "en_us", ResolveLocale("", "", supported))
+}
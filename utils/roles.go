@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"github.com/labstack/echo"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/models"
+	"gitlab.com/teamliquid-dev/decks-of-runeterra/doruneterraapi-go/types"
+)
+
+// RoleMiddleware only lets through requests whose auth token belongs to a user with at least the given role
+func RoleMiddleware(role models.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := UserFromContext(c)
+			if err != nil {
+				return err
+			}
+
+			if !user.HasRole(role) {
+				return types.Forbidden("You do not have permission to do this")
+			}
+
+			return next(c)
+		}
+	}
+}